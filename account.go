@@ -1,20 +1,27 @@
 package valr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Balance struct {
 	Currency  string
-	Available float64 `json:",string"`
-	Reserved  float64 `json:",string"`
-	Total     float64 `json:",string"`
+	Available decimal.Decimal
+	Reserved  decimal.Decimal
+	Total     decimal.Decimal
 }
 
 func (v *Valr) GetBalance() (balances []Balance, err error) {
-	resp, err := v.client.do("GET", "/account/balances", []byte(""), true)
+	return v.GetBalanceCtx(context.Background())
+}
+
+func (v *Valr) GetBalanceCtx(ctx context.Context) (balances []Balance, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/account/balances", []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -23,21 +30,21 @@ func (v *Valr) GetBalance() (balances []Balance, err error) {
 }
 
 type TransactionType struct {
-	Type        string
+	Type        TransactionKind
 	Description string
 }
 
 type Transaction struct {
 	TransactionType TransactionType
 	DebitCurrency   string
-	DebitValue      float64 `json:",string"`
+	DebitValue      decimal.Decimal
 	CreditCurrency  string
-	CreditValue     float64 `json:",string"`
+	CreditValue     decimal.Decimal
 	FeeCurrency     string
-	FeeValue        float64 `json:",string"`
+	FeeValue        decimal.Decimal
 	EventAt         time.Time
 	AdditionalInfo  struct {
-		CostPerCoin        float64
+		CostPerCoin        decimal.Decimal
 		CostPerCoinSymbol  string
 		CurrencyPairSymbol string
 		OrderId            string
@@ -45,6 +52,19 @@ type Transaction struct {
 	ID string
 }
 
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type transaction Transaction
+	aux := struct {
+		*transaction
+		EventAt jsonTime
+	}{transaction: (*transaction)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.EventAt = time.Time(aux.EventAt)
+	return nil
+}
+
 type TransactionFilter struct {
 	Skip            string
 	Limit           string
@@ -66,8 +86,12 @@ func NewTransactionFilter() TransactionFilter {
 }
 
 func (v *Valr) GetTransactionHistory() (history []Transaction, err error) {
+	return v.GetTransactionHistoryCtx(context.Background())
+}
+
+func (v *Valr) GetTransactionHistoryCtx(ctx context.Context) (history []Transaction, err error) {
 	path := "/account/transactionhistory?skip=0&limit=100"
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -76,8 +100,12 @@ func (v *Valr) GetTransactionHistory() (history []Transaction, err error) {
 }
 
 func (v *Valr) GetTransactionHistorySkipAndLimit(skip uint32, limit uint32) (history []Transaction, err error) {
+	return v.GetTransactionHistorySkipAndLimitCtx(context.Background(), skip, limit)
+}
+
+func (v *Valr) GetTransactionHistorySkipAndLimitCtx(ctx context.Context, skip uint32, limit uint32) (history []Transaction, err error) {
 	path := fmt.Sprintf("/account/transactionhistory?skip=%d&limit=%d", skip, limit)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -98,6 +126,10 @@ func addParams(params, name, value string) string {
 }
 
 func (v *Valr) GetTransactionHistoryFiltered(filter *TransactionFilter) (history []Transaction, err error) {
+	return v.GetTransactionHistoryFilteredCtx(context.Background(), filter)
+}
+
+func (v *Valr) GetTransactionHistoryFilteredCtx(ctx context.Context, filter *TransactionFilter) (history []Transaction, err error) {
 	params := ""
 	if filter != nil {
 		params = addParams("", "skip", filter.Skip)
@@ -110,7 +142,7 @@ func (v *Valr) GetTransactionHistoryFiltered(filter *TransactionFilter) (history
 
 	path := "/account/transactionhistory" + params
 
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -119,8 +151,12 @@ func (v *Valr) GetTransactionHistoryFiltered(filter *TransactionFilter) (history
 }
 
 func (v *Valr) GetTransactionHistoryLimitById(limit uint32, id string) (history []Transaction, err error) {
+	return v.GetTransactionHistoryLimitByIdCtx(context.Background(), limit, id)
+}
+
+func (v *Valr) GetTransactionHistoryLimitByIdCtx(ctx context.Context, limit uint32, id string) (history []Transaction, err error) {
 	path := fmt.Sprintf("/account/transactionhistory?limit=%d&beforeId=%s", limit, id)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -129,8 +165,12 @@ func (v *Valr) GetTransactionHistoryLimitById(limit uint32, id string) (history
 }
 
 func (v *Valr) GetTransactionHistoryForCurrencyPair(pair string, limit uint32) (history []Transaction, err error) {
+	return v.GetTransactionHistoryForCurrencyPairCtx(context.Background(), pair, limit)
+}
+
+func (v *Valr) GetTransactionHistoryForCurrencyPairCtx(ctx context.Context, pair string, limit uint32) (history []Transaction, err error) {
 	path := fmt.Sprintf("/account/%s/tradehistory?limit=%d", pair, limit)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,224 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const accountStreamPath = "/ws/account"
+
+type AccountEventType string
+
+const (
+	AccountAuthenticated           AccountEventType = "AUTHENTICATED"
+	AccountBalanceUpdate           AccountEventType = "BALANCE_UPDATE"
+	AccountOpenOrdersUpdate        AccountEventType = "OPEN_ORDERS_UPDATE"
+	AccountOrderStatusUpdate       AccountEventType = "ORDER_STATUS_UPDATE"
+	AccountNewTrade                AccountEventType = "NEW_ACCOUNT_TRADE"
+	AccountNewHistoryRecord        AccountEventType = "NEW_ACCOUNT_HISTORY_RECORD"
+	AccountOrderProcessed          AccountEventType = "ORDER_PROCESSED"
+	AccountFailedCancelOrder       AccountEventType = "FAILED_CANCEL_ORDER"
+	AccountInstantOrderCompleted   AccountEventType = "INSTANT_ORDER_COMPLETED"
+	AccountNewPendingReceive       AccountEventType = "NEW_PENDING_RECEIVE"
+	AccountSendStatusUpdate        AccountEventType = "SEND_STATUS_UPDATE"
+	AccountPendingSendConfirmation AccountEventType = "PENDING_SEND_CONFIRMATION"
+)
+
+type BalanceUpdate struct {
+	Currency  Currency
+	Available decimal.Decimal
+	Reserved  decimal.Decimal
+	Total     decimal.Decimal
+	UpdatedAt time.Time
+}
+
+func (b *BalanceUpdate) UnmarshalJSON(data []byte) error {
+	type balanceUpdate BalanceUpdate
+	aux := struct {
+		*balanceUpdate
+		UpdatedAt jsonTime
+	}{balanceUpdate: (*balanceUpdate)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.UpdatedAt = time.Time(aux.UpdatedAt)
+	return nil
+}
+
+// AccountTrade is a trade one of the account's orders took part in
+type AccountTrade struct {
+	Price           decimal.Decimal
+	Quantity        decimal.Decimal
+	CurrencyPair    string
+	TradedAt        time.Time
+	Side            OrderSide
+	OrderID         string
+	CustomerOrderID string
+	ID              string
+}
+
+func (t *AccountTrade) UnmarshalJSON(data []byte) error {
+	type accountTrade AccountTrade
+	aux := struct {
+		*accountTrade
+		TradedAt jsonTime
+	}{accountTrade: (*accountTrade)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.TradedAt = time.Time(aux.TradedAt)
+	return nil
+}
+
+// OrderProcessed tells whether an order placed by the account was accepted by the matching engine
+type OrderProcessed struct {
+	OrderID       string
+	Success       bool
+	FailureReason string
+}
+
+type FailedCancelOrder struct {
+	OrderID string
+	Message string
+}
+
+// AccountEvent is a message from the account stream, only the field matching Type is set.
+// Data holds the raw payload, also for types this package does not decode.
+type AccountEvent struct {
+	Type              AccountEventType
+	Balance           *BalanceUpdate
+	OpenOrders        []OpenOrder
+	OrderStatus       *OrderStatus
+	Trade             *AccountTrade
+	Transaction       *Transaction
+	OrderProcessed    *OrderProcessed
+	FailedCancelOrder *FailedCancelOrder
+	Data              json.RawMessage
+}
+
+func decodeAccountEvent(msg *wsMessage) (event AccountEvent, err error) {
+	event = AccountEvent{Type: AccountEventType(msg.Type), Data: msg.Data}
+	var target interface{}
+	switch event.Type {
+	case AccountBalanceUpdate:
+		target = &event.Balance
+	case AccountOpenOrdersUpdate:
+		target = &event.OpenOrders
+	case AccountOrderStatusUpdate:
+		target = &event.OrderStatus
+	case AccountNewTrade:
+		target = &event.Trade
+	case AccountNewHistoryRecord:
+		target = &event.Transaction
+	case AccountOrderProcessed:
+		target = &event.OrderProcessed
+	case AccountFailedCancelOrder:
+		target = &event.FailedCancelOrder
+	}
+	if target != nil && len(msg.Data) > 0 {
+		err = json.Unmarshal(msg.Data, target)
+	}
+	return
+}
+
+// AccountStream delivers the authenticated account events of Valr's /ws/account stream.
+// The connection is kept alive and re-established as configured by WithStreamConfig, see States.
+type AccountStream struct {
+	*wsStream
+	valr   *Valr
+	events chan AccountEvent
+
+	// queue holds the events read from the connection until deliver hands them to events,
+	// so the read loop never waits on a slow consumer and order requests keep being answered
+	queueMu sync.Mutex
+	queue   []AccountEvent
+	queued  chan struct{}
+	ended   chan struct{}
+
+	// pending are the requests waiting for a response, by client message id
+	pendingMu sync.Mutex
+	pending   map[string]chan *wsMessage
+}
+
+// NewAccountStream connects to the account stream with v's credentials and subaccount
+func (v *Valr) NewAccountStream(ctx context.Context) (*AccountStream, error) {
+	s := &AccountStream{
+		valr:    v,
+		events:  make(chan AccountEvent, 256),
+		queued:  make(chan struct{}, 1),
+		ended:   make(chan struct{}),
+		pending: make(map[string]chan *wsMessage),
+	}
+	stream, err := v.client.openStream(ctx, accountStreamPath, s.handle, func() { close(s.ended) })
+	if err != nil {
+		return nil, err
+	}
+	s.wsStream = stream
+	go s.deliver()
+	stream.start()
+	return s, nil
+}
+
+// Events returns the channel events are delivered on, it is closed when the stream ends.
+// Err tells why it ended. Responses to the stream's order requests are not delivered.
+// No event is dropped: events wait in memory until they are read.
+func (s *AccountStream) Events() <-chan AccountEvent {
+	return s.events
+}
+
+func (s *AccountStream) handle(msg *wsMessage) bool {
+	if s.resolve(msg) {
+		return true
+	}
+	event, err := decodeAccountEvent(msg)
+	if err != nil {
+		s.client.logger.Warn("valr account event not decoded", "type", msg.Type, "error", err)
+	}
+	s.queueMu.Lock()
+	s.queue = append(s.queue, event)
+	s.queueMu.Unlock()
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// deliver moves queued events to the events channel in order. Once the stream ends the
+// remaining events are still delivered, unless Close was called, then events is closed.
+func (s *AccountStream) deliver() {
+	defer close(s.events)
+	ended := false
+	for {
+		s.queueMu.Lock()
+		batch := s.queue
+		s.queue = nil
+		s.queueMu.Unlock()
+
+		for _, event := range batch {
+			select {
+			case s.events <- event:
+			case <-s.done:
+				return
+			}
+		}
+		if len(batch) > 0 {
+			continue
+		}
+		if ended {
+			return
+		}
+		select {
+		case <-s.queued:
+		case <-s.ended:
+			// handle is not called anymore, drain what it queued last
+			ended = true
+		case <-s.done:
+			return
+		}
+	}
+}
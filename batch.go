@@ -0,0 +1,239 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxBatchSize is the largest number of operations Valr accepts in one batch
+const MaxBatchSize = 20
+
+var (
+	// ErrBatchEmpty is returned when sending a batch without operations
+	ErrBatchEmpty = errors.New("valr: empty batch")
+	// ErrBatchFull is returned when more than MaxBatchSize operations are added to a batch
+	ErrBatchFull = fmt.Errorf("valr: batch holds at most %d operations", MaxBatchSize)
+)
+
+// BatchOperationType is the type of an operation in a batch
+type BatchOperationType string
+
+const (
+	BatchPlaceLimit     BatchOperationType = "PLACE_LIMIT"
+	BatchPlaceMarket    BatchOperationType = "PLACE_MARKET"
+	BatchPlaceStopLimit BatchOperationType = "PLACE_STOP_LIMIT"
+	BatchCancelOrder    BatchOperationType = "CANCEL_ORDER"
+)
+
+type batchOperation struct {
+	Type BatchOperationType `json:"type"`
+	Data interface{}        `json:"data"`
+}
+
+type batchRequest struct {
+	Requests []batchOperation `json:"requests"`
+}
+
+// Batch accumulates up to MaxBatchSize order placements and cancellations to send them in
+// a single request. Adding operations returns the batch to chain calls, an operation over the
+// limit is dropped and makes Send fail with ErrBatchFull. A Batch is not safe for concurrent use.
+type Batch struct {
+	valr       *Valr
+	operations []batchOperation
+	err        error
+}
+
+// Batch returns an empty batch sent through v
+func (v *Valr) Batch() *Batch {
+	return &Batch{valr: v}
+}
+
+func (b *Batch) add(operationType BatchOperationType, data interface{}) *Batch {
+	if len(b.operations) == MaxBatchSize {
+		b.err = ErrBatchFull
+		return b
+	}
+	b.operations = append(b.operations, batchOperation{operationType, data})
+	return b
+}
+
+func (b *Batch) PlaceLimitOrder(order LimitOrder) *Batch {
+	return b.add(BatchPlaceLimit, order)
+}
+
+func (b *Batch) PlaceMarketOrder(order MarketOrder) *Batch {
+	return b.add(BatchPlaceMarket, order)
+}
+
+func (b *Batch) PlaceStopLimitOrder(order StopLimitOrder) *Batch {
+	return b.add(BatchPlaceStopLimit, order)
+}
+
+func (b *Batch) CancelOrder(currencyPair, orderID string) *Batch {
+	return b.add(BatchCancelOrder, cancelOrder{OrderID: orderID, Pair: currencyPair})
+}
+
+func (b *Batch) CancelOrderByCustomerID(currencyPair, customerOrderID string) *Batch {
+	return b.add(BatchCancelOrder, cancelOrder{CustomerOrderID: customerOrderID, Pair: currencyPair})
+}
+
+// Len returns the number of operations in the batch
+func (b *Batch) Len() int {
+	return len(b.operations)
+}
+
+// BatchError is why Valr rejected an operation of a batch
+type BatchError struct {
+	Code    int
+	Message string
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("valr: batch operation rejected: %s (%d)", e.Message, e.Code)
+}
+
+// BatchOutcome is the result of one operation, Error is set when it was not accepted
+type BatchOutcome struct {
+	Type            BatchOperationType
+	Accepted        bool
+	OrderID         string
+	CustomerOrderID string
+	Error           *BatchError
+}
+
+// BatchResult holds the outcome of every operation, in the order they were added to the batch
+type BatchResult struct {
+	BatchID  uint64
+	Outcomes []BatchOutcome
+}
+
+// Failed returns the outcomes of the operations Valr did not accept
+func (r *BatchResult) Failed() (failed []BatchOutcome) {
+	for _, outcome := range r.Outcomes {
+		if !outcome.Accepted {
+			failed = append(failed, outcome)
+		}
+	}
+	return
+}
+
+func (b *Batch) Send() (result *BatchResult, err error) {
+	return b.SendCtx(context.Background())
+}
+
+// SendCtx validates the placements when the client has a PairRegistry, otherwise stop limit orders
+// are checked like PlaceStopLimitOrderCtx does, then sends every operation in one request.
+// Each operation is accepted or rejected on its own, see BatchResult.
+func (b *Batch) SendCtx(ctx context.Context) (result *BatchResult, err error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.operations) == 0 {
+		return nil, ErrBatchEmpty
+	}
+
+	v := b.valr
+	// stopLimitPairs caches, without a PairRegistry, whether each pair supports stop limit orders
+	stopLimitPairs := make(map[string]error)
+	operations := make([]batchOperation, len(b.operations))
+	for i, operation := range b.operations {
+		switch order := operation.Data.(type) {
+		case LimitOrder:
+			if v.pairs != nil {
+				err = v.pairs.ValidateLimitOrder(ctx, order)
+			}
+			order.CustomerOrderID = v.customerOrderID(order.CustomerOrderID)
+			operation.Data = order
+		case MarketOrder:
+			if v.pairs != nil {
+				err = v.pairs.ValidateMarketOrder(ctx, order)
+			}
+			order.CustomerOrderID = v.customerOrderID(order.CustomerOrderID)
+			operation.Data = order
+		case StopLimitOrder:
+			if v.pairs != nil {
+				err = v.pairs.ValidateStopLimitOrder(ctx, order)
+			} else if err = checkStopLimitType(order); err == nil {
+				pair := strings.ToUpper(order.Pair)
+				var checked bool
+				if err, checked = stopLimitPairs[pair]; !checked {
+					err = v.checkStopLimitSupported(ctx, pair)
+					stopLimitPairs[pair] = err
+				}
+			}
+			order.CustomerOrderID = v.customerOrderID(order.CustomerOrderID)
+			operation.Data = order
+		}
+		if err != nil {
+			return nil, fmt.Errorf("valr: batch operation %d: %w", i, err)
+		}
+		operations[i] = operation
+	}
+
+	body, err := structToBytes(batchRequest{operations})
+	if err != nil {
+		return
+	}
+
+	resp, err := v.client.doCtx(ctx, "POST", "/batch/orders", body, true)
+	if err != nil {
+		return
+	}
+
+	// Valr returns the outcomes under "requests", in the order of the operations
+	var response struct {
+		BatchID  uint64 `json:"batchId"`
+		Outcomes []struct {
+			Accepted        bool        `json:"accepted"`
+			OrderID         string      `json:"orderId"`
+			CustomerOrderID string      `json:"customerOrderId"`
+			Error           *BatchError `json:"error"`
+		} `json:"requests"`
+	}
+	if err = json.Unmarshal(resp, &response); err != nil {
+		return
+	}
+	if len(response.Outcomes) != len(operations) {
+		return nil, fmt.Errorf("valr: batch %d returned %d outcomes for %d operations",
+			response.BatchID, len(response.Outcomes), len(operations))
+	}
+
+	result = &BatchResult{BatchID: response.BatchID, Outcomes: make([]BatchOutcome, len(operations))}
+	for i, operation := range operations {
+		res := response.Outcomes[i]
+		outcome := BatchOutcome{
+			Type:            operation.Type,
+			Accepted:        res.Accepted,
+			OrderID:         res.OrderID,
+			CustomerOrderID: res.CustomerOrderID,
+			Error:           res.Error,
+		}
+		if outcome.CustomerOrderID == "" {
+			outcome.CustomerOrderID = customerOrderIDOf(operation.Data)
+		}
+		if outcome.OrderID == "" {
+			if cancel, ok := operation.Data.(cancelOrder); ok {
+				outcome.OrderID = cancel.OrderID
+			}
+		}
+		result.Outcomes[i] = outcome
+	}
+	return
+}
+
+func customerOrderIDOf(data interface{}) string {
+	switch order := data.(type) {
+	case LimitOrder:
+		return order.CustomerOrderID
+	case MarketOrder:
+		return order.CustomerOrderID
+	case StopLimitOrder:
+		return order.CustomerOrderID
+	case cancelOrder:
+		return order.CustomerOrderID
+	}
+	return ""
+}
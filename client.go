@@ -2,166 +2,211 @@ package valr
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/hex"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
-	"net/http/httputil"
-	"strconv"
 	"strings"
 	"time"
 )
 
-func signRequest(apiSecret, method, path, body string, timestamp time.Time) (string, string) {
-	// Create a new Keyed-Hash Message Authentication Code (HMAC) using SHA512 and API Secret
-	mac := hmac.New(sha512.New, []byte(apiSecret))
-	// Convert timestamp to nanoseconds then divide by 1000000 to get the milliseconds
-	timestampString := strconv.FormatInt(timestamp.UnixNano()/1000000, 10)
-
-	mac.Write([]byte(timestampString))
-	mac.Write([]byte(strings.ToUpper(method)))
-	mac.Write([]byte(path))
-	mac.Write([]byte(body))
-	// Gets the byte hash from HMAC and converts it into a hex string
-	return hex.EncodeToString(mac.Sum(nil)), timestampString
-}
+const defaultTimeout = 30 * time.Second
+
+// ErrTimeout is returned when a request takes longer than the client's http timeout
+var ErrTimeout = errors.New("timeout on reading data from Valr API")
 
 type client struct {
 	apiKey      string
-	apiSecret   string
+	signer      Signer
 	httpClient  *http.Client
 	httpTimeout time.Duration
 	debug       bool
+	logger      Logger
+	userAgent   string
 	httpBase    string
 	apiVersion  string
 	wsBase      string
+	// streamConfig applies to every websocket stream opened by the client
+	streamConfig StreamConfig
+	limiter      *RateLimiter
+	middlewares  []Middleware
+	retry        *RetryPolicy
+	clock        *clockSync
+	// subaccountID is sent and signed with every request when not empty
+	subaccountID string
+	// customSigner is set once a signer replaced the HMAC signer of the api secret
+	customSigner bool
 }
 
-func NewClient(apiKey, apiSecret string) (c *client) {
-	return &client{apiKey, apiSecret, &http.Client{}, 30 * time.Second, false, HttpBase, ApiVersion, WsBase}
+// newClient returns a client with the defaults NewClient options are applied to
+func newClient() *client {
+	return &client{
+		signer:       NewHMACSigner(""),
+		httpClient:   &http.Client{},
+		httpBase:     HttpBase,
+		apiVersion:   ApiVersion,
+		wsBase:       WsBase,
+		retry:        DefaultRetryPolicy(),
+		clock:        &clockSync{},
+		logger:       nopLogger{},
+		streamConfig: DefaultStreamConfig(),
+	}
 }
 
-func NewClientWithCustomHttpConfig(apiKey, apiSecret string, httpClient *http.Client) (c *client) {
-	timeout := httpClient.Timeout
-	if timeout <= 0 {
-		timeout = 30 * time.Second
-	}
-	return &client{apiKey, apiSecret, httpClient, timeout, false, HttpBase, ApiVersion, WsBase}
+func (c *client) setHttpBase(base string) {
+	c.httpBase = base
 }
 
-func NewClientWithCustomTimeout(apiKey, apiSecret string, timeout time.Duration) (c *client) {
-	return &client{apiKey, apiSecret, &http.Client{}, timeout, false, HttpBase, ApiVersion, WsBase}
+func (c *client) setApiVersion(version string) {
+	c.apiVersion = version
 }
 
-func (c client) dumpRequest(r *http.Request) {
-	if r == nil {
-		log.Print("dumpReq ok: <nil>")
-		return
-	}
-	dump, err := httputil.DumpRequest(r, true)
-	if err != nil {
-		log.Print("dumpReq err:", err)
-	} else {
-		log.Print("dumpReq ok:", string(dump))
-	}
+func (c *client) setWsBase(base string) {
+	c.wsBase = base
 }
 
-func (c client) dumpResponse(r *http.Response) {
-	if r == nil {
-		log.Print("dumpResponse ok: <nil>")
-		return
-	}
-	dump, err := httputil.DumpResponse(r, true)
-	if err != nil {
-		log.Print("dumpResponse err:", err)
-	} else {
-		log.Print("dumpResponse ok:", string(dump))
+func (c *client) setRateLimiter(limiter *RateLimiter) {
+	c.limiter = limiter
+}
+
+func (c *client) setSigner(signer Signer) {
+	c.signer = signer
+	c.customSigner = true
+}
+
+func (c *client) setDebug(enable bool) {
+	c.debug = enable
+	if _, ok := c.logger.(nopLogger); enable && ok {
+		c.logger = defaultDebugLogger()
 	}
 }
 
-func (c *client) setHttpBase(base string) {
-	c.httpBase = base
+func (c *client) setRetryPolicy(policy *RetryPolicy) {
+	c.retry = policy
 }
 
-func (c *client) setApiVersion(version string) {
-	c.apiVersion = version
+// send is the innermost Handler, it does the HTTP request bounded by the client's http timeout.
+// The request is cancelled through its context, so nothing is left in flight once this returns.
+func (c *client) send(req *http.Request) (*Response, error) {
+	ctx := req.Context()
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.httpTimeout)
+	defer cancel()
+
+	resp, err := c.httpClient.Do(req.WithContext(timeoutCtx))
+	if err != nil {
+		return nil, sendError(ctx, timeoutCtx, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, sendError(ctx, timeoutCtx, err)
+	}
+	return &Response{resp.StatusCode, resp.Status, resp.Header, body}, nil
 }
 
-func (c *client) setWsBase(base string) {
-	c.wsBase = base
+func sendError(ctx, timeoutCtx context.Context, err error) error {
+	// Only report our own timeout, a cancelled/expired caller context is returned as is
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
 }
 
-// doTimeoutRequest do a HTTP request with timeout
-func (c *client) doTimeoutRequest(timer *time.Timer, req *http.Request) (*http.Response, error) {
-	// Do the request in the background so we can check the timeout
-	type result struct {
-		resp *http.Response
-		err  error
-	}
-	done := make(chan result, 1)
-	go func() {
-		if c.debug {
-			c.dumpRequest(req)
+// doCtx sends the request, retrying it according to the retry policy when it is idempotent
+func (c *client) doCtx(ctx context.Context, method, path string, data []byte, authNeeded bool) (response []byte, err error) {
+	attempts := 1
+	if c.retry != nil && c.retry.MaxAttempts > 1 && isIdempotent(method, path, data) {
+		attempts = c.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err = c.doOnce(ctx, attempt, method, path, data, authNeeded)
+		if err == nil || attempt >= attempts {
+			return
 		}
-		resp, err := c.httpClient.Do(req)
-		if c.debug {
-			c.dumpResponse(resp)
+		retry, wait := c.retry.retryable(err)
+		if !retry {
+			return
+		}
+		if backoff := c.retry.backoff(attempt); backoff > wait {
+			wait = backoff
+		}
+		c.logger.Warn("valr retrying request", "method", method, "path", path, "attempt", attempt, "wait", wait, "error", err)
+		if sleepErr := sleepCtx(ctx, wait); sleepErr != nil {
+			return
 		}
-		done <- result{resp, err}
-	}()
-	// Wait for the read or the timeout
-	select {
-	case r := <-done:
-		return r.resp, r.err
-	case <-timer.C:
-		return nil, errors.New("timeout on reading data from Valr API")
 	}
 }
 
-func (c *client) do(method, path string, data []byte, authNeeded bool) (response []byte, err error) {
-	connectTimer := time.NewTimer(c.httpTimeout)
+// doOnce makes a single attempt, the request is signed with a fresh timestamp every time
+func (c *client) doOnce(ctx context.Context, attempt int, method, path string, data []byte, authNeeded bool) (response []byte, err error) {
+	group := endpointGroup(path)
+	if c.limiter != nil {
+		if err = c.limiter.Wait(ctx, group); err != nil {
+			return
+		}
+	}
 
 	url := fmt.Sprintf("%s/%s/%s", c.httpBase, c.apiVersion, strings.Trim(path, "/"))
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(data))
 	if err != nil {
 		return
 	}
 
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json;charset=utf-8")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
 	if authNeeded {
 		signaturePath := fmt.Sprintf("/%s/%s", c.apiVersion, strings.Trim(path, "/"))
-		currentTime := time.Now()
-		signature, timestamp := signRequest(c.apiSecret,
-			method,
-			signaturePath,
-			string(data),
-			currentTime)
+		timestamp := formatTimestamp(c.clock.now())
+		var signature string
+		signature, err = c.signer.Sign(timestamp, method, signaturePath, string(data), c.subaccountID)
+		if err != nil {
+			return
+		}
 
 		req.Header.Add("X-VALR-API-KEY", c.apiKey)
 		req.Header.Add("X-VALR-SIGNATURE", signature)
 		req.Header.Add("X-VALR-TIMESTAMP", timestamp)
+		if c.subaccountID != "" {
+			req.Header.Add("X-VALR-SUB-ACCOUNT-ID", c.subaccountID)
+		}
+	}
+
+	if c.debug {
+		c.logger.Debug("valr request dump", "method", method, "path", path,
+			"header", redactHeaders(req.Header), "body", redactBody(data))
 	}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+	start := time.Now()
+	resp, err := chainMiddleware(c.middlewares, c.send)(req)
+	latency := time.Since(start)
 	if err != nil {
+		c.logger.Warn("valr request failed", "method", method, "path", path, "attempt", attempt, "latency", latency, "error", err)
 		return
 	}
+	response = resp.Body
 
-	defer resp.Body.Close()
-	response, err = ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return response, err
+	c.logger.Debug("valr request", "method", method, "path", path, "status", resp.StatusCode, "attempt", attempt, "latency", latency)
+	if c.debug {
+		c.logger.Debug("valr response dump", "method", method, "path", path, "status", resp.StatusCode,
+			"header", redactHeaders(resp.Header), "body", redactBody(response))
 	}
 
 	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 && resp.StatusCode != 203 {
-		err = errors.New(resp.Status + ": " + string(response))
+		err = newAPIError(resp, method, path)
+		if resp.StatusCode == http.StatusTooManyRequests && c.limiter != nil {
+			c.limiter.Pause(group, retryAfter(resp.Header, time.Second))
+		}
 	}
 	return response, err
 }
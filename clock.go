@@ -0,0 +1,137 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidInterval is returned by StartClockSync for a non positive interval
+var ErrInvalidInterval = errors.New("valr: clock sync interval must be positive")
+
+// clockSync holds the measured offset between Valr's clock and the local one,
+// it is applied to the timestamp of every signed request
+type clockSync struct {
+	offset int64 // nanoseconds, accessed atomically
+
+	mu        sync.Mutex
+	threshold time.Duration
+	alarm     func(offset time.Duration)
+	cancel    context.CancelFunc
+}
+
+func (c *clockSync) now() time.Time {
+	return time.Now().Add(c.getOffset())
+}
+
+func (c *clockSync) getOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.offset))
+}
+
+func (c *clockSync) setOffset(offset time.Duration) {
+	atomic.StoreInt64(&c.offset, int64(offset))
+
+	c.mu.Lock()
+	threshold, alarm := c.threshold, c.alarm
+	c.mu.Unlock()
+	if alarm != nil && threshold > 0 && (offset > threshold || offset < -threshold) {
+		alarm(offset)
+	}
+}
+
+// measureClockOffset asks Valr for its time and returns the offset to the local clock,
+// corrected by half the round trip time
+func (c *client) measureClockOffset(ctx context.Context) (time.Duration, error) {
+	sent := time.Now()
+	resp, err := c.doOnce(ctx, 1, "GET", "/public/time", []byte(""), false)
+	received := time.Now()
+	if err != nil {
+		return 0, err
+	}
+
+	var serverTime ServerTime
+	if err := json.Unmarshal(resp, &serverTime); err != nil {
+		return 0, err
+	}
+	server := serverTime.Time
+	if server.IsZero() {
+		server = time.Unix(int64(serverTime.EpochTime), 0)
+	}
+
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return server.Sub(midpoint), nil
+}
+
+// SyncClock measures the offset to Valr's clock once and applies it to request signing
+func (v *Valr) SyncClock(ctx context.Context) (time.Duration, error) {
+	offset, err := v.client.measureClockOffset(ctx)
+	if err != nil {
+		return 0, err
+	}
+	v.client.clock.setOffset(offset)
+	return offset, nil
+}
+
+// StartClockSync syncs the clock now and then every interval until ctx is done or StopClockSync is called.
+// Failed periodic syncs keep the last measured offset.
+func (v *Valr) StartClockSync(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+	if _, err := v.SyncClock(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	clock := v.client.clock
+	clock.mu.Lock()
+	if clock.cancel != nil {
+		clock.cancel()
+	}
+	clock.cancel = cancel
+	clock.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := v.SyncClock(ctx); err != nil && ctx.Err() == nil {
+					v.client.logger.Warn("valr clock sync failed", "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// StopClockSync stops the periodic sync started by StartClockSync, the last offset stays applied
+func (v *Valr) StopClockSync() {
+	clock := v.client.clock
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if clock.cancel != nil {
+		clock.cancel()
+		clock.cancel = nil
+	}
+}
+
+// ClockOffset returns the last measured offset, positive when Valr's clock is ahead of ours
+func (v *Valr) ClockOffset() time.Duration {
+	return v.client.clock.getOffset()
+}
+
+// SetClockDriftAlarm calls alarm whenever a measured offset exceeds threshold in either direction
+func (v *Valr) SetClockDriftAlarm(threshold time.Duration, alarm func(offset time.Duration)) {
+	clock := v.client.clock
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.threshold = threshold
+	clock.alarm = alarm
+}
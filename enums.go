@@ -0,0 +1,202 @@
+package valr
+
+import "strings"
+
+// The string enums below keep values Valr adds later as is, use IsKnown to detect them.
+
+// OrderStatusType is the state of an order
+type OrderStatusType string
+
+const (
+	OrderStatusPlaced                           OrderStatusType = "Placed"
+	OrderStatusActive                           OrderStatusType = "Active"
+	OrderStatusPartiallyFilled                  OrderStatusType = "Partially Filled"
+	OrderStatusFilled                           OrderStatusType = "Filled"
+	OrderStatusCancelled                        OrderStatusType = "Cancelled"
+	OrderStatusFailed                           OrderStatusType = "Failed"
+	OrderStatusInstantOrderBalanceReserved      OrderStatusType = "Instant Order Balance Reserved"
+	OrderStatusInstantOrderBalanceReserveFailed OrderStatusType = "Instant Order Balance Reserve Failed"
+	OrderStatusInstantOrderCompleted            OrderStatusType = "Instant Order Completed"
+)
+
+var openOrderStatuses = map[string]bool{
+	"placed":                         true,
+	"active":                         true,
+	"partially filled":               true,
+	"instant order balance reserved": true,
+}
+
+var terminalOrderStatuses = map[string]bool{
+	"filled":                               true,
+	"cancelled":                            true,
+	"failed":                               true,
+	"instant order balance reserve failed": true,
+	"instant order completed":              true,
+}
+
+// IsOpen reports whether the order can still trade
+func (s OrderStatusType) IsOpen() bool {
+	return openOrderStatuses[strings.ToLower(string(s))]
+}
+
+// IsTerminal reports whether the order is done and will not change anymore
+func (s OrderStatusType) IsTerminal() bool {
+	return terminalOrderStatuses[strings.ToLower(string(s))]
+}
+
+func (s OrderStatusType) IsKnown() bool {
+	return s.IsOpen() || s.IsTerminal()
+}
+
+// OrderType is the type of an order as reported in its status
+type OrderType string
+
+const (
+	OrderTypeLimit           OrderType = "limit"
+	OrderTypeLimitPostOnly   OrderType = "limit post-only"
+	OrderTypeMarket          OrderType = "market"
+	OrderTypeSimple          OrderType = "simple"
+	OrderTypeStopLossLimit   OrderType = "stop-loss-limit"
+	OrderTypeTakeProfitLimit OrderType = "take-profit-limit"
+)
+
+func (t OrderType) IsKnown() bool {
+	switch OrderType(strings.ToLower(string(t))) {
+	case OrderTypeLimit, OrderTypeLimitPostOnly, OrderTypeMarket, OrderTypeSimple,
+		OrderTypeStopLossLimit, OrderTypeTakeProfitLimit:
+		return true
+	}
+	return false
+}
+
+// PairOrderType is an order type a currency pair supports, see GetOrderTypesForCurrencyPair
+type PairOrderType string
+
+const (
+	PairOrderTypeLimit     PairOrderType = "PLACE_LIMIT"
+	PairOrderTypeMarket    PairOrderType = "PLACE_MARKET"
+	PairOrderTypeStopLimit PairOrderType = "PLACE_STOP_LIMIT"
+	PairOrderTypeSimple    PairOrderType = "SIMPLE"
+)
+
+func (t PairOrderType) IsKnown() bool {
+	switch t {
+	case PairOrderTypeLimit, PairOrderTypeMarket, PairOrderTypeStopLimit, PairOrderTypeSimple:
+		return true
+	}
+	return false
+}
+
+// StopLimitType is the variant of a StopLimitOrder
+type StopLimitType string
+
+const (
+	StopLossLimit   StopLimitType = "STOP_LOSS_LIMIT"
+	TakeProfitLimit StopLimitType = "TAKE_PROFIT_LIMIT"
+)
+
+func (t StopLimitType) IsKnown() bool {
+	return t == StopLossLimit || t == TakeProfitLimit
+}
+
+// TimeInForce tells how long an order stays on the book, Valr defaults to GoodTillCancelled
+type TimeInForce string
+
+const (
+	GoodTillCancelled TimeInForce = "GTC"
+	FillOrKill        TimeInForce = "FOK"
+	ImmediateOrCancel TimeInForce = "IOC"
+)
+
+func (t TimeInForce) IsKnown() bool {
+	switch t {
+	case GoodTillCancelled, FillOrKill, ImmediateOrCancel:
+		return true
+	}
+	return false
+}
+
+// ModifyMatchStrategy tells Valr what to do when a modified order would match another order of the account
+type ModifyMatchStrategy string
+
+const (
+	ModifyCancelOriginal ModifyMatchStrategy = "CANCEL_ORIGINAL"
+	ModifyKeepOriginal   ModifyMatchStrategy = "KEEP_ORIGINAL"
+)
+
+// TransactionKind is the type of an account transaction
+type TransactionKind string
+
+const (
+	TransactionLimitBuy                   TransactionKind = "LIMIT_BUY"
+	TransactionLimitSell                  TransactionKind = "LIMIT_SELL"
+	TransactionMarketBuy                  TransactionKind = "MARKET_BUY"
+	TransactionMarketSell                 TransactionKind = "MARKET_SELL"
+	TransactionSimpleBuy                  TransactionKind = "SIMPLE_BUY"
+	TransactionSimpleSell                 TransactionKind = "SIMPLE_SELL"
+	TransactionAutoBuy                    TransactionKind = "AUTO_BUY"
+	TransactionMakerReward                TransactionKind = "MAKER_REWARD"
+	TransactionBlockchainReceive          TransactionKind = "BLOCKCHAIN_RECEIVE"
+	TransactionBlockchainSend             TransactionKind = "BLOCKCHAIN_SEND"
+	TransactionFiatDeposit                TransactionKind = "FIAT_DEPOSIT"
+	TransactionFiatWithdrawal             TransactionKind = "FIAT_WITHDRAWAL"
+	TransactionFiatWithdrawalReversal     TransactionKind = "FIAT_WITHDRAWAL_REVERSAL"
+	TransactionReferralRebate             TransactionKind = "REFERRAL_REBATE"
+	TransactionReferralReward             TransactionKind = "REFERRAL_REWARD"
+	TransactionPromotionalRebate          TransactionKind = "PROMOTIONAL_REBATE"
+	TransactionInternalTransfer           TransactionKind = "INTERNAL_TRANSFER"
+	TransactionPaymentSent                TransactionKind = "PAYMENT_SENT"
+	TransactionPaymentReceived            TransactionKind = "PAYMENT_RECEIVED"
+	TransactionPaymentReversed            TransactionKind = "PAYMENT_REVERSED"
+	TransactionPaymentReward              TransactionKind = "PAYMENT_REWARD"
+	TransactionOffChainBlockchainWithdraw TransactionKind = "OFF_CHAIN_BLOCKCHAIN_WITHDRAW"
+	TransactionOffChainBlockchainDeposit  TransactionKind = "OFF_CHAIN_BLOCKCHAIN_DEPOSIT"
+)
+
+var knownTransactionKinds = map[TransactionKind]bool{
+	TransactionLimitBuy: true, TransactionLimitSell: true, TransactionMarketBuy: true, TransactionMarketSell: true,
+	TransactionSimpleBuy: true, TransactionSimpleSell: true, TransactionAutoBuy: true, TransactionMakerReward: true,
+	TransactionBlockchainReceive: true, TransactionBlockchainSend: true, TransactionFiatDeposit: true,
+	TransactionFiatWithdrawal: true, TransactionFiatWithdrawalReversal: true, TransactionReferralRebate: true,
+	TransactionReferralReward: true, TransactionPromotionalRebate: true, TransactionInternalTransfer: true,
+	TransactionPaymentSent: true, TransactionPaymentReceived: true, TransactionPaymentReversed: true,
+	TransactionPaymentReward: true, TransactionOffChainBlockchainWithdraw: true, TransactionOffChainBlockchainDeposit: true,
+}
+
+func (k TransactionKind) IsKnown() bool {
+	return knownTransactionKinds[k]
+}
+
+// WithdrawalState is the state of a crypto withdrawal
+type WithdrawalState string
+
+const (
+	WithdrawalInitiated  WithdrawalState = "Initiated"
+	WithdrawalPending    WithdrawalState = "Pending"
+	WithdrawalVerified   WithdrawalState = "Verified"
+	WithdrawalProcessing WithdrawalState = "Processing"
+	WithdrawalCompleted  WithdrawalState = "Completed"
+	WithdrawalFailed     WithdrawalState = "Failed"
+	WithdrawalCancelled  WithdrawalState = "Cancelled"
+)
+
+// withdrawalStates maps the lower cased known states to whether they are terminal
+var withdrawalStates = map[string]bool{
+	"initiated":  false,
+	"pending":    false,
+	"verified":   false,
+	"processing": false,
+	"completed":  true,
+	"failed":     true,
+	"cancelled":  true,
+}
+
+func (s WithdrawalState) IsKnown() bool {
+	_, ok := withdrawalStates[strings.ToLower(string(s))]
+	return ok
+}
+
+// IsTerminal reports whether the withdrawal will not change anymore
+func (s WithdrawalState) IsTerminal() bool {
+	return withdrawalStates[strings.ToLower(string(s))]
+}
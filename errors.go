@@ -0,0 +1,138 @@
+package valr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrOrderAlreadyFilled is returned by ModifyOrder for an order that filled before the change,
+// it also matches the APIError Valr returns for it with errors.Is
+var ErrOrderAlreadyFilled = errors.New("valr: order already filled")
+
+// ErrOrderClosed is returned by ModifyOrder for an order that was cancelled or failed before the change
+var ErrOrderClosed = errors.New("valr: order cancelled or failed")
+
+// Error codes Valr sets in the body of failed requests, see APIError.Code. Valr's api docs
+// (https://docs.valr.com) do not publish a table of these codes, so the values below could not be
+// checked against a documented source. The Is* helpers therefore match the message as well.
+const (
+	CodeInsufficientBalance = -6
+	CodeOrderAlreadyFilled  = -15
+)
+
+// APIError is returned for every non successful response from the Valr API.
+// Use errors.As to inspect it, or one of the Is* helpers below.
+type APIError struct {
+	StatusCode int
+	Status     string
+	// Code and Message are parsed from Valr's error body, Code is 0 when the body carries none
+	Code    int
+	Message string
+	Method  string
+	Path    string
+	Header  http.Header
+	Body    []byte
+}
+
+func (e *APIError) Error() string {
+	return e.Status + ": " + string(e.Body)
+}
+
+// IsRateLimited reports whether Valr rejected the request for exceeding a rate limit
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsUnauthorized reports whether the api key, signature or timestamp was rejected
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether the requested resource (order, withdrawal, pair...) does not exist
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsInsufficientBalance reports whether the account lacked funds for the request,
+// by its error code or "insufficient" in the message
+func (e *APIError) IsInsufficientBalance() bool {
+	return e.Code == CodeInsufficientBalance || strings.Contains(strings.ToLower(e.Message), "insufficient")
+}
+
+// IsOrderAlreadyFilled reports whether the request failed because the order has already filled,
+// by its error code or the message
+func (e *APIError) IsOrderAlreadyFilled() bool {
+	if e.Code == CodeOrderAlreadyFilled {
+		return true
+	}
+	message := strings.ToLower(e.Message)
+	return strings.Contains(message, "already filled") || strings.Contains(message, "been filled") ||
+		strings.Contains(message, "fully filled")
+}
+
+// Is lets errors.Is match an APIError against the sentinel errors it stands for
+func (e *APIError) Is(target error) bool {
+	return target == ErrOrderAlreadyFilled && e.IsOrderAlreadyFilled()
+}
+
+func newAPIError(resp *Response, method, path string) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     method,
+		Path:       path,
+		Header:     resp.Header,
+		Body:       resp.Body,
+	}
+
+	var payload struct {
+		Code    int
+		Message string
+	}
+	if err := json.Unmarshal(resp.Body, &payload); err == nil {
+		apiErr.Code = payload.Code
+		apiErr.Message = payload.Message
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(resp.Body))
+	}
+	return apiErr
+}
+
+func asAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// IsRateLimited reports whether err is an APIError caused by a rate limit
+func IsRateLimited(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.IsRateLimited()
+}
+
+// IsUnauthorized reports whether err is an APIError caused by rejected credentials
+func IsUnauthorized(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.IsUnauthorized()
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource
+func IsNotFound(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.IsNotFound()
+}
+
+// IsInsufficientBalance reports whether err is an APIError caused by a lack of funds
+func IsInsufficientBalance(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.IsInsufficientBalance()
+}
+
+// IsOrderAlreadyFilled reports whether err is an APIError caused by changing a filled order
+func IsOrderAlreadyFilled(err error) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && apiErr.IsOrderAlreadyFilled()
+}
@@ -1,81 +1,589 @@
 package valr
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type LimitOrder struct {
-	Side            OrderSide `json:"side"`
-	Quantity        float64   `json:"quantity"`
-	Price           float64   `json:"price"`
-	Pair            string    `json:"pair"`
-	PostOnly        bool      `json:"postOnly"`
-	CustomerOrderID string    `json:"customerOrderId"`
+	Side            OrderSide       `json:"side"`
+	Quantity        decimal.Decimal `json:"quantity"`
+	Price           decimal.Decimal `json:"price"`
+	Pair            string          `json:"pair"`
+	PostOnly        bool            `json:"postOnly"`
+	CustomerOrderID string          `json:"customerOrderId,omitempty"`
 }
 
 type MarketOrder struct {
-	Side            OrderSide `json:"side"`
-	BaseAmount      float64   `json:"baseAmount"`
-	QuoteAmount     float64   `json:"quoteAmount"`
-	Pair            string    `json:"pair"`
-	CustomerOrderID string    `json:"customerOrderId"`
+	Side            OrderSide       `json:"side"`
+	BaseAmount      decimal.Decimal `json:"baseAmount"`
+	QuoteAmount     decimal.Decimal `json:"quoteAmount"`
+	Pair            string          `json:"pair"`
+	CustomerOrderID string          `json:"customerOrderId,omitempty"`
+}
+
+// StopLimitOrder is a limit order placed once the market trades at StopPrice
+type StopLimitOrder struct {
+	Side            OrderSide       `json:"side"`
+	Quantity        decimal.Decimal `json:"quantity"`
+	Price           decimal.Decimal `json:"price"`
+	Pair            string          `json:"pair"`
+	StopPrice       decimal.Decimal `json:"stopPrice"`
+	Type            StopLimitType   `json:"type"`
+	TimeInForce     TimeInForce     `json:"timeInForce,omitempty"`
+	CustomerOrderID string          `json:"customerOrderId,omitempty"`
+}
+
+// OrderModification amends a resting limit order in place. Set one of OrderID and CustomerOrderID,
+// and at most one of NewTotalQuantity and NewRemainingQuantity. Nil fields are left unchanged.
+type OrderModification struct {
+	OrderID              string              `json:"orderId,omitempty"`
+	CustomerOrderID      string              `json:"customerOrderId,omitempty"`
+	Pair                 string              `json:"pair"`
+	ModifyMatchStrategy  ModifyMatchStrategy `json:"modifyMatchStrategy,omitempty"`
+	NewPrice             *decimal.Decimal    `json:"newPrice,omitempty"`
+	NewTotalQuantity     *decimal.Decimal    `json:"newTotalQuantity,omitempty"`
+	NewRemainingQuantity *decimal.Decimal    `json:"newRemainingQuantity,omitempty"`
 }
 
 type OrderStatus struct {
 	OrderID           string
-	OrderStatusType   string
+	OrderStatusType   OrderStatusType
+	CurrencyPair      string
+	OriginalPrice     decimal.Decimal
+	RemainingQuantity decimal.Decimal
+	OriginalQuantity  decimal.Decimal
+	FilledPercentage  decimal.Decimal
+	OrderSide         OrderSide
+	OrderType         OrderType
+	FailedReason      string
+	CustomerOrderID   string
+	OrderUpdatedAt    time.Time
+	OrderCreatedAt    time.Time
+}
+
+func (o *OrderStatus) UnmarshalJSON(data []byte) error {
+	type orderStatus OrderStatus
+	aux := struct {
+		*orderStatus
+		OrderUpdatedAt jsonTime
+		OrderCreatedAt jsonTime
+	}{orderStatus: (*orderStatus)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.OrderUpdatedAt = time.Time(aux.OrderUpdatedAt)
+	o.OrderCreatedAt = time.Time(aux.OrderCreatedAt)
+	return nil
+}
+
+// cancelOrder identifies the order to cancel by either OrderID or CustomerOrderID
+type cancelOrder struct {
+	OrderID         string `json:"orderId,omitempty"`
+	CustomerOrderID string `json:"customerOrderId,omitempty"`
+	Pair            string `json:"pair"`
+}
+
+// CancelledOrder is an order cancelled by CancelAllOrders or CancelAllOrdersForPair
+type CancelledOrder struct {
+	OrderID      string
+	CurrencyPair string
+}
+
+// OpenOrder is an order resting on the order book. The account stream sets Quantity,
+// GetOpenOrders sets RemainingQuantity and the fields following it.
+type OpenOrder struct {
+	OrderID           string
+	Side              OrderSide
+	Quantity          decimal.Decimal
+	Price             decimal.Decimal
+	CurrencyPair      string
+	CreatedAt         time.Time
+	OriginalQuantity  decimal.Decimal
+	FilledPercentage  decimal.Decimal
+	CustomerOrderID   string
+	RemainingQuantity decimal.Decimal
+	UpdatedAt         time.Time
+	Status            OrderStatusType
+	Type              OrderType
+	TimeInForce       TimeInForce
+	StopPrice         decimal.Decimal
+}
+
+func (o *OpenOrder) UnmarshalJSON(data []byte) error {
+	type openOrder OpenOrder
+	aux := struct {
+		*openOrder
+		CreatedAt jsonTime
+		UpdatedAt jsonTime
+	}{openOrder: (*openOrder)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.CreatedAt = time.Time(aux.CreatedAt)
+	o.UpdatedAt = time.Time(aux.UpdatedAt)
+	return nil
+}
+
+// HistoricalOrder is an order from the order history, filled, cancelled or failed
+type HistoricalOrder struct {
+	OrderID           string
+	CustomerOrderID   string
+	OrderStatusType   OrderStatusType
 	CurrencyPair      string
-	OriginalPrice     float64 `json:",string"`
-	RemainingQuantity float64 `json:",string"`
-	OriginalQuantity  float64 `json:",string"`
-	FilledPercentage  float64 `json:",string"`
+	AveragePrice      decimal.Decimal
+	OriginalPrice     decimal.Decimal
+	RemainingQuantity decimal.Decimal
+	OriginalQuantity  decimal.Decimal
+	Total             decimal.Decimal
+	TotalFee          decimal.Decimal
+	FeeCurrency       string
 	OrderSide         OrderSide
-	OrderType         string
+	OrderType         OrderType
 	FailedReason      string
+	TimeInForce       TimeInForce
+	OrderUpdatedAt    time.Time
+	OrderCreatedAt    time.Time
+}
+
+func (o *HistoricalOrder) UnmarshalJSON(data []byte) error {
+	type historicalOrder HistoricalOrder
+	aux := struct {
+		*historicalOrder
+		OrderUpdatedAt jsonTime
+		OrderCreatedAt jsonTime
+	}{historicalOrder: (*historicalOrder)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.OrderUpdatedAt = time.Time(aux.OrderUpdatedAt)
+	o.OrderCreatedAt = time.Time(aux.OrderCreatedAt)
+	return nil
+}
+
+// OrderEvent is one step in the life of an order, e.g. its placement, a fill or its cancellation.
+// The Executed fields are set for fills.
+type OrderEvent struct {
+	OrderID           string
 	CustomerOrderID   string
-	OrderUpdatedAt    string
-	OrderCreatedAt    string
+	OrderStatusType   OrderStatusType
+	CurrencyPair      string
+	OriginalPrice     decimal.Decimal
+	RemainingQuantity decimal.Decimal
+	OriginalQuantity  decimal.Decimal
+	OrderSide         OrderSide
+	OrderType         OrderType
+	FailedReason      string
+	TimeInForce       TimeInForce
+	ExecutedPrice     decimal.Decimal
+	ExecutedQuantity  decimal.Decimal
+	ExecutedFee       decimal.Decimal
+	OrderUpdatedAt    time.Time
+	OrderCreatedAt    time.Time
+}
+
+func (e *OrderEvent) UnmarshalJSON(data []byte) error {
+	type orderEvent OrderEvent
+	aux := struct {
+		*orderEvent
+		OrderUpdatedAt jsonTime
+		OrderCreatedAt jsonTime
+	}{orderEvent: (*orderEvent)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.OrderUpdatedAt = time.Time(aux.OrderUpdatedAt)
+	e.OrderCreatedAt = time.Time(aux.OrderCreatedAt)
+	return nil
+}
+
+// NewCustomerOrderID returns a random customer order id, a UUID as accepted by Valr.
+// Store it before placing the order to find the order again with GetOrderStatusByCustomerID.
+func NewCustomerOrderID() string {
+	return newUUID()
+}
+
+// customerOrderID returns id, or a new customer order id when id is blank and they are generated
+func (v *Valr) customerOrderID(id string) string {
+	if id == "" && v.autoCustomerOrderID {
+		return NewCustomerOrderID()
+	}
+	return id
 }
 
 func (v *Valr) PlaceLimitOrder(order LimitOrder) (id *OrderID, err error) {
+	return v.PlaceLimitOrderCtx(context.Background(), order)
+}
+
+func (v *Valr) PlaceLimitOrderCtx(ctx context.Context, order LimitOrder) (id *OrderID, err error) {
 	path := "/orders/limit"
+	if v.pairs != nil {
+		if err = v.pairs.ValidateLimitOrder(ctx, order); err != nil {
+			return
+		}
+	}
+	order.CustomerOrderID = v.customerOrderID(order.CustomerOrderID)
 
 	body, err := structToBytes(order)
 	if err != nil {
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
-	err = json.Unmarshal(resp, &id)
+	if err = json.Unmarshal(resp, &id); err == nil && id != nil {
+		id.CustomerOrderID = order.CustomerOrderID
+	}
 	return
 }
 
 func (v *Valr) PlaceMarketOrder(order MarketOrder) (id *OrderID, err error) {
+	return v.PlaceMarketOrderCtx(context.Background(), order)
+}
+
+func (v *Valr) PlaceMarketOrderCtx(ctx context.Context, order MarketOrder) (id *OrderID, err error) {
 	path := "/orders/market"
+	if v.pairs != nil {
+		if err = v.pairs.ValidateMarketOrder(ctx, order); err != nil {
+			return
+		}
+	}
+	order.CustomerOrderID = v.customerOrderID(order.CustomerOrderID)
+
+	body, err := structToBytes(order)
+	if err != nil {
+		return
+	}
+
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(resp, &id); err == nil && id != nil {
+		id.CustomerOrderID = order.CustomerOrderID
+	}
+	return
+}
+
+func (v *Valr) PlaceStopLimitOrder(order StopLimitOrder) (id *OrderID, err error) {
+	return v.PlaceStopLimitOrderCtx(context.Background(), order)
+}
+
+// PlaceStopLimitOrderCtx places a stop-loss or take-profit limit order. Without WithPairValidation
+// the order's type is checked and the pair's order types are fetched to check it supports stop limit orders.
+func (v *Valr) PlaceStopLimitOrderCtx(ctx context.Context, order StopLimitOrder) (id *OrderID, err error) {
+	path := "/orders/stop/limit"
+	if v.pairs != nil {
+		err = v.pairs.ValidateStopLimitOrder(ctx, order)
+	} else if err = checkStopLimitType(order); err == nil {
+		err = v.checkStopLimitSupported(ctx, order.Pair)
+	}
+	if err != nil {
+		return
+	}
+	order.CustomerOrderID = v.customerOrderID(order.CustomerOrderID)
 
 	body, err := structToBytes(order)
 	if err != nil {
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
-	err = json.Unmarshal(resp, &id)
+	if err = json.Unmarshal(resp, &id); err == nil && id != nil {
+		id.CustomerOrderID = order.CustomerOrderID
+	}
 	return
 }
 
+func checkStopLimitType(order StopLimitOrder) error {
+	if !order.Type.IsKnown() {
+		return &OrderValidationError{strings.ToUpper(order.Pair), "type",
+			fmt.Sprintf("must be %s or %s", StopLossLimit, TakeProfitLimit)}
+	}
+	return nil
+}
+
+func (v *Valr) checkStopLimitSupported(ctx context.Context, currencyPair string) error {
+	orderTypes, err := v.GetOrderTypesForCurrencyPairCtx(ctx, currencyPair)
+	if err != nil {
+		return err
+	}
+	if !supportsOrderType(orderTypes, PairOrderTypeStopLimit) {
+		return &OrderValidationError{strings.ToUpper(currencyPair), "type", "stop limit orders are not supported"}
+	}
+	return nil
+}
+
+func supportsOrderType(orderTypes []PairOrderType, orderType PairOrderType) bool {
+	for _, t := range orderTypes {
+		if t == orderType {
+			return true
+		}
+	}
+	return false
+}
+
 func (v *Valr) GetOrderStatus(currencyPair, id string) (status *OrderStatus, err error) {
+	return v.GetOrderStatusCtx(context.Background(), currencyPair, id)
+}
+
+func (v *Valr) GetOrderStatusCtx(ctx context.Context, currencyPair, id string) (status *OrderStatus, err error) {
 	path := fmt.Sprintf("/orders/%s/orderid/%s", currencyPair, id)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &status)
+	return
+}
+
+func (v *Valr) GetOrderStatusByCustomerID(currencyPair, customerOrderID string) (status *OrderStatus, err error) {
+	return v.GetOrderStatusByCustomerIDCtx(context.Background(), currencyPair, customerOrderID)
+}
+
+func (v *Valr) GetOrderStatusByCustomerIDCtx(ctx context.Context, currencyPair, customerOrderID string) (status *OrderStatus, err error) {
+	path := fmt.Sprintf("/orders/%s/customerorderid/%s", currencyPair, customerOrderID)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
 	err = json.Unmarshal(resp, &status)
 	return
 }
+
+// CancelOrder requests the cancellation of an order. Valr cancels asynchronously, use GetOrderStatus
+// or the account stream to learn whether it succeeded.
+func (v *Valr) CancelOrder(currencyPair, orderID string) error {
+	return v.CancelOrderCtx(context.Background(), currencyPair, orderID)
+}
+
+func (v *Valr) CancelOrderCtx(ctx context.Context, currencyPair, orderID string) error {
+	return v.cancelOrderCtx(ctx, cancelOrder{OrderID: orderID, Pair: currencyPair})
+}
+
+// CancelOrderByCustomerID requests the cancellation of the order placed with customerOrderID
+func (v *Valr) CancelOrderByCustomerID(currencyPair, customerOrderID string) error {
+	return v.CancelOrderByCustomerIDCtx(context.Background(), currencyPair, customerOrderID)
+}
+
+func (v *Valr) CancelOrderByCustomerIDCtx(ctx context.Context, currencyPair, customerOrderID string) error {
+	return v.cancelOrderCtx(ctx, cancelOrder{CustomerOrderID: customerOrderID, Pair: currencyPair})
+}
+
+func (v *Valr) cancelOrderCtx(ctx context.Context, order cancelOrder) error {
+	body, err := structToBytes(order)
+	if err != nil {
+		return err
+	}
+	_, err = v.client.doCtx(ctx, "DELETE", "/orders/order", body, true)
+	return err
+}
+
+// CancelAllOrders cancels every open order of the account
+func (v *Valr) CancelAllOrders() (orders []CancelledOrder, err error) {
+	return v.CancelAllOrdersCtx(context.Background())
+}
+
+func (v *Valr) CancelAllOrdersCtx(ctx context.Context) (orders []CancelledOrder, err error) {
+	resp, err := v.client.doCtx(ctx, "DELETE", "/orders", []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &orders)
+	return
+}
+
+// CancelAllOrdersForPair cancels every open order of the account on currencyPair
+func (v *Valr) CancelAllOrdersForPair(currencyPair string) (orders []CancelledOrder, err error) {
+	return v.CancelAllOrdersForPairCtx(context.Background(), currencyPair)
+}
+
+func (v *Valr) CancelAllOrdersForPairCtx(ctx context.Context, currencyPair string) (orders []CancelledOrder, err error) {
+	path := fmt.Sprintf("/orders/%s", currencyPair)
+	resp, err := v.client.doCtx(ctx, "DELETE", path, []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &orders)
+	return
+}
+
+func (v *Valr) GetOpenOrders() (orders []OpenOrder, err error) {
+	return v.GetOpenOrdersCtx(context.Background())
+}
+
+func (v *Valr) GetOpenOrdersCtx(ctx context.Context) (orders []OpenOrder, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/orders/open", []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &orders)
+	return
+}
+
+// orderHistoryPageSize is the largest page Valr returns from the order history
+const orderHistoryPageSize = 100
+
+func (v *Valr) GetOrderHistory(skip, limit uint32) (history []HistoricalOrder, err error) {
+	return v.GetOrderHistoryCtx(context.Background(), skip, limit)
+}
+
+func (v *Valr) GetOrderHistoryCtx(ctx context.Context, skip, limit uint32) (history []HistoricalOrder, err error) {
+	path := fmt.Sprintf("/orders/history?skip=%d&limit=%d", skip, limit)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &history)
+	return
+}
+
+// GetAllOrderHistory pages through the whole order history, most recent first
+func (v *Valr) GetAllOrderHistory() (history []HistoricalOrder, err error) {
+	return v.GetAllOrderHistoryCtx(context.Background())
+}
+
+func (v *Valr) GetAllOrderHistoryCtx(ctx context.Context) (history []HistoricalOrder, err error) {
+	for skip := uint32(0); ; skip += orderHistoryPageSize {
+		page, err := v.GetOrderHistoryCtx(ctx, skip, orderHistoryPageSize)
+		if err != nil {
+			return history, err
+		}
+		history = append(history, page...)
+		if len(page) < orderHistoryPageSize {
+			return history, nil
+		}
+	}
+}
+
+func (v *Valr) GetOrderHistorySummary(orderID string) (order *HistoricalOrder, err error) {
+	return v.GetOrderHistorySummaryCtx(context.Background(), orderID)
+}
+
+func (v *Valr) GetOrderHistorySummaryCtx(ctx context.Context, orderID string) (order *HistoricalOrder, err error) {
+	path := fmt.Sprintf("/orders/history/summary/orderid/%s", orderID)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &order)
+	return
+}
+
+func (v *Valr) GetOrderHistorySummaryByCustomerID(customerOrderID string) (order *HistoricalOrder, err error) {
+	return v.GetOrderHistorySummaryByCustomerIDCtx(context.Background(), customerOrderID)
+}
+
+func (v *Valr) GetOrderHistorySummaryByCustomerIDCtx(ctx context.Context, customerOrderID string) (order *HistoricalOrder, err error) {
+	path := fmt.Sprintf("/orders/history/summary/customerorderid/%s", customerOrderID)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &order)
+	return
+}
+
+// GetOrderHistoryDetail returns every event of an order, one per fill
+func (v *Valr) GetOrderHistoryDetail(orderID string) (events []OrderEvent, err error) {
+	return v.GetOrderHistoryDetailCtx(context.Background(), orderID)
+}
+
+func (v *Valr) GetOrderHistoryDetailCtx(ctx context.Context, orderID string) (events []OrderEvent, err error) {
+	path := fmt.Sprintf("/orders/history/detail/orderid/%s", orderID)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &events)
+	return
+}
+
+func (v *Valr) ModifyOrder(modification OrderModification) (status *OrderStatus, err error) {
+	return v.ModifyOrderCtx(context.Background(), modification)
+}
+
+// ErrModifyPending is returned by ModifyOrder with the order's status when the status
+// does not show the change yet after modifyStatusAttempts lookups
+var ErrModifyPending = errors.New("valr: order modification not applied yet")
+
+const (
+	modifyStatusAttempts = 5
+	modifyStatusInterval = 100 * time.Millisecond
+)
+
+// ModifyOrderCtx amends an order instead of cancelling and placing it again, and returns the order's
+// status once it shows the change. When the order filled before the change the error matches
+// ErrOrderAlreadyFilled with errors.Is, when it was cancelled or failed it is ErrOrderClosed.
+// The order's status is returned along when known.
+func (v *Valr) ModifyOrderCtx(ctx context.Context, modification OrderModification) (status *OrderStatus, err error) {
+	path := "/orders/modify"
+	invalid := func(field, reason string) error {
+		return &OrderValidationError{strings.ToUpper(modification.Pair), field, reason}
+	}
+	if (modification.OrderID == "") == (modification.CustomerOrderID == "") {
+		return nil, invalid("orderId/customerOrderId", "exactly one must be set")
+	}
+	if modification.NewTotalQuantity != nil && modification.NewRemainingQuantity != nil {
+		return nil, invalid("newTotalQuantity/newRemainingQuantity", "at most one can be set")
+	}
+	if modification.NewPrice == nil && modification.NewTotalQuantity == nil && modification.NewRemainingQuantity == nil {
+		return nil, invalid("newPrice/newTotalQuantity/newRemainingQuantity", "at least one must be set")
+	}
+
+	body, err := structToBytes(modification)
+	if err != nil {
+		return
+	}
+
+	if _, err = v.client.doCtx(ctx, "PUT", path, body, true); err != nil {
+		return
+	}
+
+	// Valr applies the modification asynchronously, wait for the status to show it
+	for attempt := 1; ; attempt++ {
+		if modification.OrderID != "" {
+			status, err = v.GetOrderStatusCtx(ctx, modification.Pair, modification.OrderID)
+		} else {
+			status, err = v.GetOrderStatusByCustomerIDCtx(ctx, modification.Pair, modification.CustomerOrderID)
+		}
+		// An applied modification may fill the order, so the change is checked before the state
+		switch {
+		case err != nil:
+			return
+		case modification.appliedTo(status):
+			return status, nil
+		case strings.EqualFold(string(status.OrderStatusType), string(OrderStatusFilled)):
+			return status, ErrOrderAlreadyFilled
+		case status.OrderStatusType.IsTerminal():
+			return status, ErrOrderClosed
+		case attempt == modifyStatusAttempts:
+			return status, ErrModifyPending
+		}
+		if err = sleepCtx(ctx, modifyStatusInterval); err != nil {
+			return
+		}
+	}
+}
+
+// appliedTo reports whether status shows every change of m. Fills after the change lower the
+// remaining quantity below NewRemainingQuantity, the original quantity still covers it.
+func (m OrderModification) appliedTo(status *OrderStatus) bool {
+	remainingApplied := m.NewRemainingQuantity == nil ||
+		(status.RemainingQuantity.LessThanOrEqual(*m.NewRemainingQuantity) &&
+			status.OriginalQuantity.GreaterThanOrEqual(*m.NewRemainingQuantity))
+	return remainingApplied &&
+		(m.NewPrice == nil || status.OriginalPrice.Equal(*m.NewPrice)) &&
+		(m.NewTotalQuantity == nil || status.OriginalQuantity.Equal(*m.NewTotalQuantity))
+}
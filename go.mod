@@ -3,6 +3,7 @@ module github.com/sasiedu/go-valr
 go 1.13
 
 require (
+	github.com/gorilla/websocket v1.4.2
 	github.com/joho/godotenv v1.3.0
 	github.com/shopspring/decimal v1.2.0
 	github.com/stretchr/testify v1.5.1
@@ -0,0 +1,147 @@
+package valr
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Logger receives structured log records, args are alternating keys and values.
+// *slog.Logger satisfies it, NewStdLogger adapts the standard log package.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+// NewStdLogger returns a Logger writing records at or above level as "LEVEL msg key=value" lines to logger
+func NewStdLogger(logger *log.Logger, level LogLevel) Logger {
+	return &stdLogger{logger, level}
+}
+
+func (l *stdLogger) log(level LogLevel, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteString(" ")
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " !BADKEY=%v", args[i])
+		}
+	}
+	l.logger.Print(b.String())
+}
+
+func (l *stdLogger) Debug(msg string, args ...interface{}) { l.log(LevelDebug, msg, args) }
+func (l *stdLogger) Info(msg string, args ...interface{})  { l.log(LevelInfo, msg, args) }
+func (l *stdLogger) Warn(msg string, args ...interface{})  { l.log(LevelWarn, msg, args) }
+func (l *stdLogger) Error(msg string, args ...interface{}) { l.log(LevelError, msg, args) }
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// defaultDebugLogger is used by SetDebug when no Logger was configured
+func defaultDebugLogger() Logger {
+	return NewStdLogger(log.New(os.Stderr, "", log.LstdFlags), LevelDebug)
+}
+
+const redacted = "[REDACTED]"
+
+var redactedHeaders = map[string]bool{
+	"X-Valr-Api-Key":   true,
+	"X-Valr-Signature": true,
+}
+
+// redactedFields are json fields, compared case insensitively, never written to logs
+var redactedFields = map[string]bool{
+	"accountnumber": true,
+	"accountholder": true,
+	"branchcode":    true,
+	"apikey":        true,
+	"apisecret":     true,
+	"secret":        true,
+	"signature":     true,
+}
+
+func redactHeaders(header http.Header) http.Header {
+	clean := make(http.Header, len(header))
+	for name, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			clean[name] = []string{redacted}
+			continue
+		}
+		clean[name] = values
+	}
+	return clean
+}
+
+// redactBody replaces sensitive fields of a json body, other bodies are returned unchanged
+func redactBody(body []byte) string {
+	var value interface{}
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return string(body)
+	}
+	clean, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(clean)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return value
+}
@@ -1,28 +1,51 @@
 package valr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Order struct {
 	Side            OrderSide
-	Quantity        float64 `json:",string"`
-	Price           float64 `json:",string"`
+	Quantity        decimal.Decimal
+	Price           decimal.Decimal
 	CurrencyPair    string
 	ID              string
-	PositionAtPrice uint8
-	OrderCount      uint8
+	PositionAtPrice uint32
+	OrderCount      uint32
 }
 
 type OrderBook struct {
-	Asks []Order
-	Bids []Order
+	Asks           []Order
+	Bids           []Order
+	LastChange     time.Time
+	SequenceNumber uint64
+}
+
+func (o *OrderBook) UnmarshalJSON(data []byte) error {
+	type orderBook OrderBook
+	aux := struct {
+		*orderBook
+		LastChange jsonTime
+	}{orderBook: (*orderBook)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.LastChange = time.Time(aux.LastChange)
+	return nil
 }
 
 func (v *Valr) GetOrderBook(currencyPair string) (orderBook *OrderBook, err error) {
+	return v.GetOrderBookCtx(context.Background(), currencyPair)
+}
+
+func (v *Valr) GetOrderBookCtx(ctx context.Context, currencyPair string) (orderBook *OrderBook, err error) {
 	path := fmt.Sprintf("/marketdata/%s/orderbook", currencyPair)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -31,8 +54,12 @@ func (v *Valr) GetOrderBook(currencyPair string) (orderBook *OrderBook, err erro
 }
 
 func (v *Valr) GetNonAggregatedOrderBook(currencyPair string) (orderBook *OrderBook, err error) {
+	return v.GetNonAggregatedOrderBookCtx(context.Background(), currencyPair)
+}
+
+func (v *Valr) GetNonAggregatedOrderBookCtx(ctx context.Context, currencyPair string) (orderBook *OrderBook, err error) {
 	path := fmt.Sprintf("/marketdata/%s/orderbook/full", currencyPair)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -41,18 +68,35 @@ func (v *Valr) GetNonAggregatedOrderBook(currencyPair string) (orderBook *OrderB
 }
 
 type Trade struct {
-	Price        float64 `json:",string"`
-	Quantity     float64 `json:",string"`
+	Price        decimal.Decimal
+	Quantity     decimal.Decimal
 	CurrencyPair string
-	TradeAt      string
+	TradeAt      time.Time `json:"tradedAt"`
 	TakerSide    OrderSide
 	SequenceID   uint32
 	ID           string
 }
 
+func (t *Trade) UnmarshalJSON(data []byte) error {
+	type trade Trade
+	aux := struct {
+		*trade
+		TradeAt jsonTime `json:"tradedAt"`
+	}{trade: (*trade)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.TradeAt = time.Time(aux.TradeAt)
+	return nil
+}
+
 func (v *Valr) GetCurrencyPairTradeHistory(currencyPair string, limit uint8) (history []Trade, err error) {
+	return v.GetCurrencyPairTradeHistoryCtx(context.Background(), currencyPair, limit)
+}
+
+func (v *Valr) GetCurrencyPairTradeHistoryCtx(ctx context.Context, currencyPair string, limit uint8) (history []Trade, err error) {
 	path := fmt.Sprintf("/marketdata/%s/tradehistory?limit=%d", currencyPair, limit)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,199 @@
+package valr
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Response is a Valr response as seen by middleware, Body has already been read
+type Response struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// Handler sends a signed request and returns its response.
+// A non 2xx status is not an error at this level, it becomes an APIError afterwards.
+type Handler func(req *http.Request) (*Response, error)
+
+// Middleware wraps a Handler to observe or alter every request made by a Valr,
+// the first middleware given to WithMiddleware is the outermost one
+type Middleware func(next Handler) Handler
+
+func chainMiddleware(middlewares []Middleware, handler Handler) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Endpoint returns a low cardinality name for a request such as "GET /v1/orders/BTCZAR/orderid/:id",
+// ids in the path are replaced by ":id". Customer order ids are free form, they are recognised
+// by the segment preceding them.
+func Endpoint(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i, segment := range segments {
+		if i > 0 && (isIDSegment(segment) || idPrefixes[strings.ToLower(segments[i-1])]) {
+			segments[i] = ":id"
+		}
+	}
+	return req.Method + " /" + strings.Join(segments, "/")
+}
+
+// idPrefixes are the path segments followed by an id
+var idPrefixes = map[string]bool{
+	"orderid":         true,
+	"customerorderid": true,
+}
+
+func isIDSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	digits, hex := 0, true
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'f', r >= 'A' && r <= 'F', r == '-':
+		default:
+			hex = false
+		}
+	}
+	// numeric ids and uuids
+	return digits == len(segment) || (hex && digits > 0 && len(segment) >= 32)
+}
+
+// DefaultLatencyBuckets are the upper bounds used by NewLatencyHistogram when none are given
+var DefaultLatencyBuckets = []time.Duration{
+	25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+	500 * time.Millisecond, time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// HistogramSnapshot holds cumulative counts, Counts[i] is the number of requests
+// that took at most Buckets[i], Count includes slower ones too
+type HistogramSnapshot struct {
+	Buckets []time.Duration
+	Counts  []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// LatencyHistogram records request latency per endpoint
+type LatencyHistogram struct {
+	buckets []time.Duration
+	mu      sync.Mutex
+	data    map[string]*HistogramSnapshot
+}
+
+func NewLatencyHistogram(buckets ...time.Duration) *LatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &LatencyHistogram{buckets: sorted, data: make(map[string]*HistogramSnapshot)}
+}
+
+func (h *LatencyHistogram) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			h.observe(Endpoint(req), time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+func (h *LatencyHistogram) observe(endpoint string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshot, ok := h.data[endpoint]
+	if !ok {
+		snapshot = &HistogramSnapshot{Buckets: h.buckets, Counts: make([]uint64, len(h.buckets))}
+		h.data[endpoint] = snapshot
+	}
+	for i, bound := range h.buckets {
+		if latency <= bound {
+			snapshot.Counts[i]++
+		}
+	}
+	snapshot.Count++
+	snapshot.Sum += latency
+}
+
+// Snapshot returns a copy of the histogram of every endpoint seen so far
+func (h *LatencyHistogram) Snapshot() map[string]HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshots := make(map[string]HistogramSnapshot, len(h.data))
+	for endpoint, snapshot := range h.data {
+		copied := *snapshot
+		copied.Counts = append([]uint64(nil), snapshot.Counts...)
+		snapshots[endpoint] = copied
+	}
+	return snapshots
+}
+
+// EndpointCount counts requests to one endpoint, Errors are requests that got no response
+type EndpointCount struct {
+	Requests uint64
+	Errors   uint64
+	ByStatus map[int]uint64
+}
+
+// EndpointCounters counts requests per endpoint and response status
+type EndpointCounters struct {
+	mu   sync.Mutex
+	data map[string]*EndpointCount
+}
+
+func NewEndpointCounters() *EndpointCounters {
+	return &EndpointCounters{data: make(map[string]*EndpointCount)}
+}
+
+func (c *EndpointCounters) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*Response, error) {
+			resp, err := next(req)
+			c.count(Endpoint(req), resp, err)
+			return resp, err
+		}
+	}
+}
+
+func (c *EndpointCounters) count(endpoint string, resp *Response, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count, ok := c.data[endpoint]
+	if !ok {
+		count = &EndpointCount{ByStatus: make(map[int]uint64)}
+		c.data[endpoint] = count
+	}
+	count.Requests++
+	if err != nil || resp == nil {
+		count.Errors++
+		return
+	}
+	count.ByStatus[resp.StatusCode]++
+}
+
+// Snapshot returns a copy of the counters of every endpoint seen so far
+func (c *EndpointCounters) Snapshot() map[string]EndpointCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshots := make(map[string]EndpointCount, len(c.data))
+	for endpoint, count := range c.data {
+		byStatus := make(map[int]uint64, len(count.ByStatus))
+		for status, n := range count.ByStatus {
+			byStatus[status] = n
+		}
+		snapshots[endpoint] = EndpointCount{count.Requests, count.Errors, byStatus}
+	}
+	return snapshots
+}
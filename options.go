@@ -0,0 +1,151 @@
+package valr
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a Valr created with NewClient
+type Option func(v *Valr)
+
+// NewClient returns a Valr configured by opts. The returned Valr is safe to share
+// between goroutines as long as the deprecated Set* methods are not used on it,
+// they race with requests in flight and are replaced by the options below.
+func NewClient(opts ...Option) *Valr {
+	v := &Valr{client: newClient()}
+	for _, opt := range opts {
+		opt(v)
+	}
+	v.client.setDebug(v.client.debug)
+	if v.client.httpTimeout <= 0 {
+		v.client.httpTimeout = v.client.httpClient.Timeout
+	}
+	if v.client.httpTimeout <= 0 {
+		v.client.httpTimeout = defaultTimeout
+	}
+	return v
+}
+
+// WithCredentials authenticates requests with apiKey, signing them with apiSecret unless WithSigner is given
+func WithCredentials(apiKey, apiSecret string) Option {
+	return func(v *Valr) {
+		v.client.apiKey = apiKey
+		if !v.client.customSigner {
+			v.client.signer = NewHMACSigner(apiSecret)
+		}
+	}
+}
+
+// WithSigner signs requests with signer instead of an api secret. Combine it with
+// WithCredentials("key", ""), in either order.
+func WithSigner(signer Signer) Option {
+	return func(v *Valr) {
+		v.client.setSigner(signer)
+	}
+}
+
+// WithHTTPClient sends requests through httpClient, its Timeout is used unless WithTimeout is given
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(v *Valr) {
+		v.client.httpClient = httpClient
+	}
+}
+
+func WithHTTPBase(base string) Option {
+	return func(v *Valr) {
+		v.client.httpBase = base
+	}
+}
+
+func WithWsBase(base string) Option {
+	return func(v *Valr) {
+		v.client.wsBase = base
+	}
+}
+
+func WithAPIVersion(version string) Option {
+	return func(v *Valr) {
+		v.client.apiVersion = version
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(v *Valr) {
+		v.client.httpTimeout = timeout
+	}
+}
+
+// WithDebug logs redacted request and response dumps at debug level,
+// to the standard logger unless WithLogger is given
+func WithDebug(enable bool) Option {
+	return func(v *Valr) {
+		v.client.debug = enable
+	}
+}
+
+// WithLogger sends structured logs to logger, e.g. a *slog.Logger. Auth headers and
+// sensitive body fields are redacted.
+func WithLogger(logger Logger) Option {
+	return func(v *Valr) {
+		if logger == nil {
+			logger = nopLogger{}
+		}
+		v.client.logger = logger
+	}
+}
+
+// WithRateLimiter throttles requests through limiter, share it between clients using the same api key
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(v *Valr) {
+		v.client.limiter = limiter
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy, nil disables retries
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(v *Valr) {
+		v.client.retry = policy
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(v *Valr) {
+		v.client.userAgent = userAgent
+	}
+}
+
+// WithSubaccount makes every request act on behalf of the subaccount with the given id
+func WithSubaccount(id string) Option {
+	return func(v *Valr) {
+		v.client.subaccountID = id
+	}
+}
+
+// WithMiddleware adds middlewares around every request, see Middleware
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(v *Valr) {
+		v.client.middlewares = append(v.client.middlewares, middlewares...)
+	}
+}
+
+// WithPairValidation validates limit, market and stop limit orders against registry before placing them
+func WithPairValidation(registry *PairRegistry) Option {
+	return func(v *Valr) {
+		v.pairs = registry
+	}
+}
+
+// WithAutoCustomerOrderID sets a NewCustomerOrderID on orders placed without a customer order id.
+// Besides letting the order be looked up before Valr returns its id, it makes retrying the placement safe.
+func WithAutoCustomerOrderID() Option {
+	return func(v *Valr) {
+		v.autoCustomerOrderID = true
+	}
+}
+
+// WithStreamConfig replaces DefaultStreamConfig for the websocket streams opened by the client
+func WithStreamConfig(config StreamConfig) Option {
+	return func(v *Valr) {
+		v.client.streamConfig = config
+	}
+}
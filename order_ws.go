@@ -0,0 +1,180 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// wsRequest is a request sent on the account stream, the response carries the same ClientMsgID
+type wsRequest struct {
+	Type        string      `json:"type"`
+	ClientMsgID string      `json:"clientMsgId"`
+	Payload     interface{} `json:"payload"`
+}
+
+// wsResponse is the data of a response to a wsRequest
+type wsResponse struct {
+	OrderID     string
+	ClientMsgID string
+	Code        int
+	Message     string
+}
+
+// clientMsgID returns the id correlating msg to a request, Valr sets it on the envelope
+// or in the data depending on the message type
+func clientMsgID(msg *wsMessage) string {
+	if msg.ClientMsgID != "" || len(msg.Data) == 0 || !strings.Contains(string(msg.Data), "clientMsgId") {
+		return msg.ClientMsgID
+	}
+	var data struct{ ClientMsgID string }
+	json.Unmarshal(msg.Data, &data)
+	return data.ClientMsgID
+}
+
+// resolve hands msg to the request waiting for it, it reports whether there was one
+func (s *AccountStream) resolve(msg *wsMessage) bool {
+	id := clientMsgID(msg)
+	if id == "" {
+		return false
+	}
+	s.pendingMu.Lock()
+	reply, ok := s.pending[id]
+	delete(s.pending, id)
+	s.pendingMu.Unlock()
+	if ok {
+		reply <- msg
+	}
+	return ok
+}
+
+// request sends a request of msgType on the stream and waits for its response. Until the context
+// has a deadline the client's timeout applies. ErrNotConnected and ErrStreamClosed are returned
+// when the request could not be sent.
+func (s *AccountStream) request(ctx context.Context, msgType string, payload interface{}) (*wsResponse, error) {
+	if _, ok := ctx.Deadline(); !ok && s.client.httpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.client.httpTimeout)
+		defer cancel()
+	}
+
+	req := wsRequest{Type: msgType, ClientMsgID: newUUID(), Payload: payload}
+	reply := make(chan *wsMessage, 1)
+	s.pendingMu.Lock()
+	s.pending[req.ClientMsgID] = reply
+	s.pendingMu.Unlock()
+	defer func() {
+		s.pendingMu.Lock()
+		delete(s.pending, req.ClientMsgID)
+		s.pendingMu.Unlock()
+	}()
+
+	start := time.Now()
+	if err := s.send(req); err != nil {
+		return nil, err
+	}
+
+	var msg *wsMessage
+	select {
+	case msg = <-reply:
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
+	case <-s.done:
+		// The request went out, unlike a failed send this does not mean it was not processed
+		return nil, fmt.Errorf("valr: %s sent but stream closed before the response", msgType)
+	}
+	s.client.logger.Info("valr websocket request", "type", msgType, "response", msg.Type, "latency", time.Since(start))
+
+	var resp wsResponse
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, &resp); err != nil {
+			return nil, err
+		}
+	}
+	failed := strings.Contains(msg.Type, "FAIL") || strings.Contains(msg.Type, "ERROR")
+	if failed || (resp.Message != "" && resp.OrderID == "") {
+		return nil, &APIError{
+			Status:  msg.Type,
+			Code:    resp.Code,
+			Message: resp.Message,
+			Method:  msgType,
+			Path:    s.path,
+			Body:    msg.Data,
+		}
+	}
+	return &resp, nil
+}
+
+// fallback reports whether a failed websocket request was never sent and may go over REST instead
+func fallback(err error) bool {
+	return err == ErrNotConnected || err == ErrStreamClosed
+}
+
+func (s *AccountStream) PlaceLimitOrderWS(order LimitOrder) (id *OrderID, err error) {
+	return s.PlaceLimitOrderWSCtx(context.Background(), order)
+}
+
+// PlaceLimitOrderWSCtx places order over the account stream, falling back to PlaceLimitOrderCtx
+// while the stream is not connected
+func (s *AccountStream) PlaceLimitOrderWSCtx(ctx context.Context, order LimitOrder) (id *OrderID, err error) {
+	if s.valr.pairs != nil {
+		if err = s.valr.pairs.ValidateLimitOrder(ctx, order); err != nil {
+			return
+		}
+	}
+	order.CustomerOrderID = s.valr.customerOrderID(order.CustomerOrderID)
+
+	resp, err := s.request(ctx, "PLACE_LIMIT_ORDER", order)
+	if fallback(err) {
+		return s.valr.PlaceLimitOrderCtx(ctx, order)
+	}
+	if err != nil {
+		return
+	}
+	return &OrderID{ID: resp.OrderID, CustomerOrderID: order.CustomerOrderID}, nil
+}
+
+func (s *AccountStream) PlaceMarketOrderWS(order MarketOrder) (id *OrderID, err error) {
+	return s.PlaceMarketOrderWSCtx(context.Background(), order)
+}
+
+// PlaceMarketOrderWSCtx places order over the account stream, falling back to PlaceMarketOrderCtx
+// while the stream is not connected
+func (s *AccountStream) PlaceMarketOrderWSCtx(ctx context.Context, order MarketOrder) (id *OrderID, err error) {
+	if s.valr.pairs != nil {
+		if err = s.valr.pairs.ValidateMarketOrder(ctx, order); err != nil {
+			return
+		}
+	}
+	order.CustomerOrderID = s.valr.customerOrderID(order.CustomerOrderID)
+
+	resp, err := s.request(ctx, "PLACE_MARKET_ORDER", order)
+	if fallback(err) {
+		return s.valr.PlaceMarketOrderCtx(ctx, order)
+	}
+	if err != nil {
+		return
+	}
+	return &OrderID{ID: resp.OrderID, CustomerOrderID: order.CustomerOrderID}, nil
+}
+
+func (s *AccountStream) CancelOrderWS(currencyPair, orderID string) error {
+	return s.CancelOrderWSCtx(context.Background(), currencyPair, orderID)
+}
+
+// CancelOrderWSCtx requests the cancellation of an order over the account stream, falling back
+// to REST while the stream is not connected. A cancel that fails in the matching engine is
+// reported later by an AccountFailedCancelOrder event.
+func (s *AccountStream) CancelOrderWSCtx(ctx context.Context, currencyPair, orderID string) error {
+	order := cancelOrder{OrderID: orderID, Pair: currencyPair}
+	_, err := s.request(ctx, "CANCEL_LIMIT_ORDER", order)
+	if fallback(err) {
+		return s.valr.cancelOrderCtx(ctx, order)
+	}
+	return err
+}
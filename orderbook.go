@@ -0,0 +1,400 @@
+package valr
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const minResyncInterval = time.Second
+
+// BookLevel is a price level of a locally maintained order book
+type BookLevel struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+	Orders   []LevelOrder
+}
+
+func newBookLevel(price decimal.Decimal, orders []LevelOrder) *BookLevel {
+	level := &BookLevel{Price: price, Orders: orders}
+	for _, order := range orders {
+		level.Quantity = level.Quantity.Add(order.Quantity)
+	}
+	return level
+}
+
+// bookSide keeps the levels of one side sorted best first
+type bookSide struct {
+	descending bool
+	levels     []*BookLevel
+}
+
+// search returns the index of price, or where it would be inserted
+func (s *bookSide) search(price decimal.Decimal) int {
+	return sort.Search(len(s.levels), func(i int) bool {
+		if s.descending {
+			return s.levels[i].Price.LessThanOrEqual(price)
+		}
+		return s.levels[i].Price.GreaterThanOrEqual(price)
+	})
+}
+
+// update replaces the orders at price, no orders removes the level
+func (s *bookSide) update(price decimal.Decimal, orders []LevelOrder) {
+	i := s.search(price)
+	found := i < len(s.levels) && s.levels[i].Price.Equal(price)
+	switch {
+	case len(orders) == 0 && found:
+		s.levels = append(s.levels[:i], s.levels[i+1:]...)
+	case len(orders) == 0:
+	case found:
+		s.levels[i] = newBookLevel(price, orders)
+	default:
+		s.levels = append(s.levels, nil)
+		copy(s.levels[i+1:], s.levels[i:])
+		s.levels[i] = newBookLevel(price, orders)
+	}
+}
+
+func (s *bookSide) depth(levels int) []BookLevel {
+	if levels <= 0 || levels > len(s.levels) {
+		levels = len(s.levels)
+	}
+	depth := make([]BookLevel, levels)
+	for i := range depth {
+		depth[i] = *s.levels[i]
+	}
+	return depth
+}
+
+type localBook struct {
+	asks       bookSide
+	bids       bookSide
+	sequence   uint64
+	lastChange time.Time
+	synced     bool
+	resyncedAt time.Time
+	// resyncScheduled is set while a throttled resync waits for minResyncInterval to pass
+	resyncScheduled bool
+}
+
+func newLocalBook() *localBook {
+	return &localBook{bids: bookSide{descending: true}}
+}
+
+func (b *localBook) apply(update *FullOrderBookUpdate) {
+	for _, level := range update.Asks {
+		b.asks.update(level.Price, level.Orders)
+	}
+	for _, level := range update.Bids {
+		b.bids.update(level.Price, level.Orders)
+	}
+	b.sequence = update.SequenceNumber
+	b.lastChange = update.LastChange
+}
+
+func (b *localBook) reset(snapshot *FullOrderBookUpdate) {
+	resyncedAt, resyncScheduled := b.resyncedAt, b.resyncScheduled
+	*b = *newLocalBook()
+	b.resyncedAt, b.resyncScheduled = resyncedAt, resyncScheduled
+	b.apply(snapshot)
+	b.synced = true
+}
+
+// snapshotFromOrderBook groups the orders of a non aggregated order book by price
+func snapshotFromOrderBook(orderBook *OrderBook) *FullOrderBookUpdate {
+	group := func(orders []Order) []PriceLevel {
+		var levels []PriceLevel
+		index := make(map[string]int)
+		for _, order := range orders {
+			key := order.Price.String()
+			i, ok := index[key]
+			if !ok {
+				i = len(levels)
+				index[key] = i
+				levels = append(levels, PriceLevel{Price: order.Price})
+			}
+			levels[i].Orders = append(levels[i].Orders, LevelOrder{order.ID, order.Quantity})
+		}
+		return levels
+	}
+	return &FullOrderBookUpdate{
+		Asks:           group(orderBook.Asks),
+		Bids:           group(orderBook.Bids),
+		LastChange:     orderBook.LastChange,
+		SequenceNumber: orderBook.SequenceNumber,
+	}
+}
+
+// BookChange notifies that the book of Pair changed, Resynced is set when it was rebuilt from a snapshot
+type BookChange struct {
+	Pair           string
+	SequenceNumber uint64
+	Resynced       bool
+}
+
+// OrderBookManager keeps full order books up to date from the trade stream. Updates are
+// checked for sequence gaps, on a gap the book is resynced from GetNonAggregatedOrderBook.
+// It is safe for concurrent use.
+type OrderBookManager struct {
+	valr    *Valr
+	stream  *TradeStream
+	changes chan BookChange
+	states  chan StreamStateEvent
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu    sync.RWMutex
+	books map[string]*localBook
+}
+
+// NewOrderBookManager opens a trade stream and maintains the full order books of pairs
+func (v *Valr) NewOrderBookManager(ctx context.Context, pairs ...string) (*OrderBookManager, error) {
+	stream, err := v.NewTradeStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &OrderBookManager{
+		valr:    v,
+		stream:  stream,
+		changes: make(chan BookChange, 1024),
+		states:  make(chan StreamStateEvent, 64),
+		books:   make(map[string]*localBook),
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	if err := m.Watch(pairs...); err != nil {
+		stream.Close()
+		return nil, err
+	}
+	go m.run()
+	go m.watchStates()
+	return m, nil
+}
+
+// Watch starts maintaining the books of pairs
+func (m *OrderBookManager) Watch(pairs ...string) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	for _, pair := range pairs {
+		pair = strings.ToUpper(pair)
+		if _, ok := m.books[pair]; !ok {
+			m.books[pair] = newLocalBook()
+		}
+	}
+	m.mu.Unlock()
+	return m.stream.Subscribe(TradeFullOrderBookUpdate, pairs...)
+}
+
+// Unwatch stops maintaining the books of pairs and drops them
+func (m *OrderBookManager) Unwatch(pairs ...string) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	for _, pair := range pairs {
+		delete(m.books, strings.ToUpper(pair))
+	}
+	m.mu.Unlock()
+	return m.stream.Unsubscribe(TradeFullOrderBookUpdate, pairs...)
+}
+
+// Changes returns the channel change notifications are sent on.
+// Notifications are dropped while the channel is full.
+func (m *OrderBookManager) Changes() <-chan BookChange {
+	return m.changes
+}
+
+// States returns the connection state changes of the underlying stream, see TradeStream.States
+func (m *OrderBookManager) States() <-chan StreamStateEvent {
+	return m.states
+}
+
+// Err returns why the underlying stream ended
+func (m *OrderBookManager) Err() error {
+	return m.stream.Err()
+}
+
+func (m *OrderBookManager) Close() error {
+	m.cancel()
+	return m.stream.Close()
+}
+
+func (m *OrderBookManager) run() {
+	defer close(m.changes)
+	for event := range m.stream.Events() {
+		if event.FullOrderBook == nil {
+			continue
+		}
+		pair := strings.ToUpper(event.Pair)
+		switch event.Type {
+		case TradeFullOrderBookSnapshot:
+			m.resetBook(pair, event.FullOrderBook)
+		case TradeFullOrderBookUpdate:
+			m.applyUpdate(pair, event.FullOrderBook)
+		}
+	}
+}
+
+// watchStates marks every book unsynced while the stream reconnects, updates are missed
+// meanwhile and the books are rebuilt from the snapshots sent on resubscription
+func (m *OrderBookManager) watchStates() {
+	defer close(m.states)
+	for state := range m.stream.States() {
+		if state.State == StreamReconnecting {
+			m.mu.Lock()
+			for _, book := range m.books {
+				book.synced = false
+			}
+			m.mu.Unlock()
+		}
+		select {
+		case m.states <- state:
+		default:
+		}
+	}
+}
+
+func (m *OrderBookManager) resetBook(pair string, snapshot *FullOrderBookUpdate) {
+	m.mu.Lock()
+	book, ok := m.books[pair]
+	if ok {
+		book.reset(snapshot)
+	}
+	m.mu.Unlock()
+	if ok {
+		m.notify(BookChange{pair, snapshot.SequenceNumber, true})
+	}
+}
+
+func (m *OrderBookManager) applyUpdate(pair string, update *FullOrderBookUpdate) {
+	m.mu.Lock()
+	book, ok := m.books[pair]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	// A book rebuilt from a snapshot without sequence number takes the next update as its base
+	inSequence := book.synced && (book.sequence == 0 || update.SequenceNumber == book.sequence+1)
+	stale := book.synced && book.sequence != 0 && update.SequenceNumber <= book.sequence
+	var resync bool
+	switch {
+	case inSequence:
+		book.apply(update)
+	case !stale:
+		// A gap: the book is not served until it is rebuilt. Resync at most once per interval
+		// so a failing resync does not hammer the api, a throttled one is scheduled instead.
+		book.synced = false
+		if wait := minResyncInterval - time.Since(book.resyncedAt); wait > 0 {
+			if !book.resyncScheduled {
+				book.resyncScheduled = true
+				time.AfterFunc(wait, func() { m.scheduledResync(pair) })
+			}
+		} else {
+			resync = true
+			book.resyncedAt = time.Now()
+		}
+	}
+	m.mu.Unlock()
+
+	if inSequence {
+		m.notify(BookChange{pair, update.SequenceNumber, false})
+	} else if resync {
+		m.resync(pair)
+	}
+}
+
+// scheduledResync runs a resync that was throttled, unless the book got rebuilt or dropped meanwhile
+func (m *OrderBookManager) scheduledResync(pair string) {
+	m.mu.Lock()
+	book, ok := m.books[pair]
+	run := ok && !book.synced && m.ctx.Err() == nil
+	if ok {
+		book.resyncScheduled = false
+	}
+	if run {
+		book.resyncedAt = time.Now()
+	}
+	m.mu.Unlock()
+	if run {
+		m.resync(pair)
+	}
+}
+
+// resync rebuilds the book of pair from the REST non aggregated order book
+func (m *OrderBookManager) resync(pair string) {
+	orderBook, err := m.valr.GetNonAggregatedOrderBookCtx(m.ctx, pair)
+	if err != nil {
+		m.valr.client.logger.Warn("valr order book resync failed", "pair", pair, "error", err)
+		m.mu.Lock()
+		if book, ok := m.books[pair]; ok {
+			book.synced = false
+		}
+		m.mu.Unlock()
+		return
+	}
+	m.valr.client.logger.Info("valr order book resynced", "pair", pair, "sequence", orderBook.SequenceNumber)
+	m.resetBook(pair, snapshotFromOrderBook(orderBook))
+}
+
+func (m *OrderBookManager) notify(change BookChange) {
+	select {
+	case m.changes <- change:
+	default:
+	}
+}
+
+func (m *OrderBookManager) book(pair string) (*localBook, bool) {
+	book, ok := m.books[strings.ToUpper(pair)]
+	return book, ok && book.synced
+}
+
+// BestBid returns the highest bid of pair, false when the book is empty or not synced
+func (m *OrderBookManager) BestBid(pair string) (BookLevel, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	book, ok := m.book(pair)
+	if !ok || len(book.bids.levels) == 0 {
+		return BookLevel{}, false
+	}
+	return *book.bids.levels[0], true
+}
+
+// BestAsk returns the lowest ask of pair, false when the book is empty or not synced
+func (m *OrderBookManager) BestAsk(pair string) (BookLevel, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	book, ok := m.book(pair)
+	if !ok || len(book.asks.levels) == 0 {
+		return BookLevel{}, false
+	}
+	return *book.asks.levels[0], true
+}
+
+// Depth returns up to levels price levels of each side best first, levels <= 0 returns the whole book
+func (m *OrderBookManager) Depth(pair string, levels int) (bids, asks []BookLevel, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	book, ok := m.book(pair)
+	if !ok {
+		return nil, nil, false
+	}
+	return book.bids.depth(levels), book.asks.depth(levels), true
+}
+
+// SequenceNumber returns the sequence number the book of pair is at
+func (m *OrderBookManager) SequenceNumber(pair string) (uint64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	book, ok := m.book(pair)
+	if !ok {
+		return 0, false
+	}
+	return book.sequence, true
+}
@@ -0,0 +1,233 @@
+package valr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnknownPair is returned by PairRegistry for symbols Valr does not list
+var ErrUnknownPair = errors.New("valr: unknown currency pair")
+
+// OrderValidationError is returned when an order breaks the limits of its currency pair
+type OrderValidationError struct {
+	Pair   string
+	Field  string
+	Reason string
+}
+
+func (e *OrderValidationError) Error() string {
+	return fmt.Sprintf("valr: invalid order for %s: %s %s", e.Pair, e.Field, e.Reason)
+}
+
+// PairRegistry caches currency pair metadata to round and validate orders locally,
+// saving a round trip to have Valr reject them. It is safe for concurrent use.
+type PairRegistry struct {
+	valr *Valr
+	ttl  time.Duration
+
+	mu         sync.RWMutex
+	pairs      map[string]CurrencyPair
+	orderTypes map[string][]PairOrderType
+	loadedAt   time.Time
+}
+
+// NewPairRegistry returns a registry loading pairs through v, which needs no credentials.
+// Pairs are reloaded when older than ttl, a ttl of 0 loads them only once.
+func NewPairRegistry(v *Valr, ttl time.Duration) *PairRegistry {
+	return &PairRegistry{valr: v, ttl: ttl}
+}
+
+// Refresh reloads every pair and the order types they support from Valr
+func (r *PairRegistry) Refresh(ctx context.Context) error {
+	currencyPairs, err := r.valr.GetCurrencyPairsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	pairOrderTypes, err := r.valr.GetAllCurrencyPairOrderTypesCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	pairs := make(map[string]CurrencyPair, len(currencyPairs))
+	for _, pair := range currencyPairs {
+		pairs[strings.ToUpper(pair.Symbol)] = pair
+	}
+	orderTypes := make(map[string][]PairOrderType, len(pairOrderTypes))
+	for _, types := range pairOrderTypes {
+		orderTypes[strings.ToUpper(types.CurrencyPair)] = types.OrderTypes
+	}
+
+	r.mu.Lock()
+	r.pairs = pairs
+	r.orderTypes = orderTypes
+	r.loadedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *PairRegistry) stale() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pairs == nil || (r.ttl > 0 && time.Since(r.loadedAt) > r.ttl)
+}
+
+// Pair returns the metadata of symbol, loading the registry first when it is empty or stale
+func (r *PairRegistry) Pair(ctx context.Context, symbol string) (pair CurrencyPair, err error) {
+	if r.stale() {
+		if err = r.Refresh(ctx); err != nil {
+			return
+		}
+	}
+
+	r.mu.RLock()
+	pair, ok := r.pairs[strings.ToUpper(symbol)]
+	r.mu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrUnknownPair, symbol)
+	}
+	return
+}
+
+// OrderTypes returns the order types symbol supports, loading the registry first when it is empty or stale
+func (r *PairRegistry) OrderTypes(ctx context.Context, symbol string) ([]PairOrderType, error) {
+	if _, err := r.Pair(ctx, symbol); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.orderTypes[strings.ToUpper(symbol)], nil
+}
+
+// RoundPrice rounds price to the nearest multiple of the pair's tick size
+func (r *PairRegistry) RoundPrice(ctx context.Context, symbol string, price decimal.Decimal) (decimal.Decimal, error) {
+	pair, err := r.Pair(ctx, symbol)
+	if err != nil {
+		return price, err
+	}
+	if !pair.TickSize.IsPositive() {
+		return price, nil
+	}
+	return price.Div(pair.TickSize).Round(0).Mul(pair.TickSize), nil
+}
+
+// RoundQuantity truncates quantity to the pair's base decimal places, it never rounds up
+func (r *PairRegistry) RoundQuantity(ctx context.Context, symbol string, quantity decimal.Decimal) (decimal.Decimal, error) {
+	pair, err := r.Pair(ctx, symbol)
+	if err != nil {
+		return quantity, err
+	}
+	return quantity.Truncate(pair.BaseDecimalPlaces), nil
+}
+
+// ValidateLimitOrder checks order against the pair's tick size, precision and min/max amounts
+func (r *PairRegistry) ValidateLimitOrder(ctx context.Context, order LimitOrder) error {
+	pair, err := r.Pair(ctx, order.Pair)
+	if err != nil {
+		return err
+	}
+	invalid := func(field, reason string) error {
+		return &OrderValidationError{pair.Symbol, field, reason}
+	}
+
+	if !pair.Active {
+		return invalid("pair", "is not active")
+	}
+	if !order.Price.IsPositive() {
+		return invalid("price", "must be positive")
+	}
+	if pair.TickSize.IsPositive() && !order.Price.Mod(pair.TickSize).IsZero() {
+		return invalid("price", fmt.Sprintf("must be a multiple of tick size %s", pair.TickSize))
+	}
+	if err := validateBaseAmount(pair, "quantity", order.Quantity); err != nil {
+		return err
+	}
+	return validateQuoteAmount(pair, "price*quantity", order.Price.Mul(order.Quantity))
+}
+
+// ValidateMarketOrder checks that exactly one of the base and quote amounts is set and within the pair's limits
+func (r *PairRegistry) ValidateMarketOrder(ctx context.Context, order MarketOrder) error {
+	pair, err := r.Pair(ctx, order.Pair)
+	if err != nil {
+		return err
+	}
+
+	if !pair.Active {
+		return &OrderValidationError{pair.Symbol, "pair", "is not active"}
+	}
+	if order.BaseAmount.IsZero() == order.QuoteAmount.IsZero() {
+		return &OrderValidationError{pair.Symbol, "baseAmount/quoteAmount", "exactly one must be set"}
+	}
+	if !order.BaseAmount.IsZero() {
+		return validateBaseAmount(pair, "baseAmount", order.BaseAmount)
+	}
+	return validateQuoteAmount(pair, "quoteAmount", order.QuoteAmount)
+}
+
+// ValidateStopLimitOrder checks that the pair supports stop limit orders and order against the pair's
+// tick size, precision and min/max amounts
+func (r *PairRegistry) ValidateStopLimitOrder(ctx context.Context, order StopLimitOrder) error {
+	orderTypes, err := r.OrderTypes(ctx, order.Pair)
+	if err != nil {
+		return err
+	}
+	pair, err := r.Pair(ctx, order.Pair)
+	if err != nil {
+		return err
+	}
+	invalid := func(field, reason string) error {
+		return &OrderValidationError{pair.Symbol, field, reason}
+	}
+
+	if !supportsOrderType(orderTypes, PairOrderTypeStopLimit) {
+		return invalid("type", "stop limit orders are not supported")
+	}
+	if !order.Type.IsKnown() {
+		return invalid("type", fmt.Sprintf("must be %s or %s", StopLossLimit, TakeProfitLimit))
+	}
+	if !order.StopPrice.IsPositive() {
+		return invalid("stopPrice", "must be positive")
+	}
+	if pair.TickSize.IsPositive() && !order.StopPrice.Mod(pair.TickSize).IsZero() {
+		return invalid("stopPrice", fmt.Sprintf("must be a multiple of tick size %s", pair.TickSize))
+	}
+	return r.ValidateLimitOrder(ctx, LimitOrder{
+		Side:     order.Side,
+		Quantity: order.Quantity,
+		Price:    order.Price,
+		Pair:     order.Pair,
+	})
+}
+
+func validateBaseAmount(pair CurrencyPair, field string, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return &OrderValidationError{pair.Symbol, field, "must be positive"}
+	}
+	if !amount.Truncate(pair.BaseDecimalPlaces).Equal(amount) {
+		return &OrderValidationError{pair.Symbol, field, fmt.Sprintf("has more than %d decimal places", pair.BaseDecimalPlaces)}
+	}
+	return validateRange(pair, field, amount, pair.MinBaseAmount, pair.MaxBaseAmount)
+}
+
+func validateQuoteAmount(pair CurrencyPair, field string, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return &OrderValidationError{pair.Symbol, field, "must be positive"}
+	}
+	return validateRange(pair, field, amount, pair.MinQuoteAmount, pair.MaxQuoteAmount)
+}
+
+// validateRange checks min <= amount <= max, a zero bound is not enforced
+func validateRange(pair CurrencyPair, field string, amount, min, max decimal.Decimal) error {
+	if min.IsPositive() && amount.LessThan(min) {
+		return &OrderValidationError{pair.Symbol, field, fmt.Sprintf("%s is below the minimum of %s", amount, min)}
+	}
+	if max.IsPositive() && amount.GreaterThan(max) {
+		return &OrderValidationError{pair.Symbol, field, fmt.Sprintf("%s is above the maximum of %s", amount, max)}
+	}
+	return nil
+}
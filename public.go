@@ -1,9 +1,13 @@
 package valr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Currency struct {
@@ -14,7 +18,11 @@ type Currency struct {
 }
 
 func (v *Valr) GetCurrencies() (currencies []Currency, err error) {
-	resp, err := v.client.do("GET", "/public/currencies", []byte(""), false)
+	return v.GetCurrenciesCtx(context.Background())
+}
+
+func (v *Valr) GetCurrenciesCtx(ctx context.Context) (currencies []Currency, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/public/currencies", []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -23,8 +31,12 @@ func (v *Valr) GetCurrencies() (currencies []Currency, err error) {
 }
 
 func (v *Valr) GetPublicOrderBook(currencyPair string) (orderBook *OrderBook, err error) {
+	return v.GetPublicOrderBookCtx(context.Background(), currencyPair)
+}
+
+func (v *Valr) GetPublicOrderBookCtx(ctx context.Context, currencyPair string) (orderBook *OrderBook, err error) {
 	path := fmt.Sprintf("/public/%s/orderbook", strings.ToUpper(currencyPair))
-	resp, err := v.client.do("GET", path, []byte(""), false)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -38,14 +50,31 @@ type CurrencyPair struct {
 	QuoteCurrency  string
 	ShortName      string
 	Active         bool
-	MinBaseAmount  float64 `json:",string"`
-	MaxBaseAmount  float64 `json:",string"`
-	MinQuoteAmount float64 `json:",string"`
-	MaxQuoteAmount float64 `json:",string"`
+	MinBaseAmount  decimal.Decimal
+	MaxBaseAmount  decimal.Decimal
+	MinQuoteAmount decimal.Decimal
+	MaxQuoteAmount decimal.Decimal
+	TickSize       decimal.Decimal
+	// BaseDecimalPlaces is the precision of quantities, QuoteDecimalPlaces derives the precision of prices
+	BaseDecimalPlaces    int32 `json:",string"`
+	MarginTradingAllowed bool
+	CurrencyPairType     string
+}
+
+// QuoteDecimalPlaces returns the number of decimal places of the pair's tick size
+func (p CurrencyPair) QuoteDecimalPlaces() int32 {
+	if p.TickSize.Exponent() >= 0 {
+		return 0
+	}
+	return -p.TickSize.Exponent()
 }
 
 func (v *Valr) GetCurrencyPairs() (currencyPairs []CurrencyPair, err error) {
-	resp, err := v.client.do("GET", "/public/pairs", []byte(""), false)
+	return v.GetCurrencyPairsCtx(context.Background())
+}
+
+func (v *Valr) GetCurrencyPairsCtx(ctx context.Context) (currencyPairs []CurrencyPair, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/public/pairs", []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -55,11 +84,15 @@ func (v *Valr) GetCurrencyPairs() (currencyPairs []CurrencyPair, err error) {
 
 type CurrencyOrderTypes struct {
 	CurrencyPair string
-	OrderTypes   []string
+	OrderTypes   []PairOrderType
 }
 
 func (v *Valr) GetAllCurrencyPairOrderTypes() (currencyPairsOrderTypes []CurrencyOrderTypes, err error) {
-	resp, err := v.client.do("GET", "/public/ordertypes", []byte(""), false)
+	return v.GetAllCurrencyPairOrderTypesCtx(context.Background())
+}
+
+func (v *Valr) GetAllCurrencyPairOrderTypesCtx(ctx context.Context) (currencyPairsOrderTypes []CurrencyOrderTypes, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/public/ordertypes", []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -67,9 +100,13 @@ func (v *Valr) GetAllCurrencyPairOrderTypes() (currencyPairsOrderTypes []Currenc
 	return
 }
 
-func (v *Valr) GetOrderTypesForCurrencyPair(currencyPair string) (orderTypes []string, err error) {
+func (v *Valr) GetOrderTypesForCurrencyPair(currencyPair string) (orderTypes []PairOrderType, err error) {
+	return v.GetOrderTypesForCurrencyPairCtx(context.Background(), currencyPair)
+}
+
+func (v *Valr) GetOrderTypesForCurrencyPairCtx(ctx context.Context, currencyPair string) (orderTypes []PairOrderType, err error) {
 	path := fmt.Sprintf("/public/%s/ordertypes", strings.ToUpper(currencyPair))
-	resp, err := v.client.do("GET", path, []byte(""), false)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -79,19 +116,36 @@ func (v *Valr) GetOrderTypesForCurrencyPair(currencyPair string) (orderTypes []s
 
 type MarketSummary struct {
 	CurrencyPair       string
-	AskPrice           float64 `json:",string"`
-	BidPrice           float64 `json:",string"`
-	LastTradedPrice    float64 `json:",string"`
-	PreviousClosePrice float64 `json:",string"`
-	BaseVolume         float64 `json:",string"`
-	HighPrice          float64 `json:",string"`
-	LowPrice           float64 `json:",string"`
-	Created            string
-	ChangeFromPrevious float64 `json:",string"`
+	AskPrice           decimal.Decimal
+	BidPrice           decimal.Decimal
+	LastTradedPrice    decimal.Decimal
+	PreviousClosePrice decimal.Decimal
+	BaseVolume         decimal.Decimal
+	HighPrice          decimal.Decimal
+	LowPrice           decimal.Decimal
+	Created            time.Time
+	ChangeFromPrevious decimal.Decimal
+}
+
+func (m *MarketSummary) UnmarshalJSON(data []byte) error {
+	type marketSummary MarketSummary
+	aux := struct {
+		*marketSummary
+		Created jsonTime
+	}{marketSummary: (*marketSummary)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	m.Created = time.Time(aux.Created)
+	return nil
 }
 
 func (v *Valr) GetAllCurrencyPairMarketSummary() (marketSummaries []MarketSummary, err error) {
-	resp, err := v.client.do("GET", "/public/marketsummary", []byte(""), false)
+	return v.GetAllCurrencyPairMarketSummaryCtx(context.Background())
+}
+
+func (v *Valr) GetAllCurrencyPairMarketSummaryCtx(ctx context.Context) (marketSummaries []MarketSummary, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/public/marketsummary", []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -100,8 +154,12 @@ func (v *Valr) GetAllCurrencyPairMarketSummary() (marketSummaries []MarketSummar
 }
 
 func (v *Valr) GetMarketSummaryForCurrencyPair(currencyPair string) (marketSummary *MarketSummary, err error) {
+	return v.GetMarketSummaryForCurrencyPairCtx(context.Background(), currencyPair)
+}
+
+func (v *Valr) GetMarketSummaryForCurrencyPairCtx(ctx context.Context, currencyPair string) (marketSummary *MarketSummary, err error) {
 	path := fmt.Sprintf("/public/%s/marketsummary", strings.ToUpper(currencyPair))
-	resp, err := v.client.do("GET", path, []byte(""), false)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -111,11 +169,28 @@ func (v *Valr) GetMarketSummaryForCurrencyPair(currencyPair string) (marketSumma
 
 type ServerTime struct {
 	EpochTime uint64
-	Time      string
+	Time      time.Time
+}
+
+func (s *ServerTime) UnmarshalJSON(data []byte) error {
+	type serverTime ServerTime
+	aux := struct {
+		*serverTime
+		Time jsonTime
+	}{serverTime: (*serverTime)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.Time = time.Time(aux.Time)
+	return nil
 }
 
 func (v *Valr) GetServerTime() (serverTime *ServerTime, err error) {
-	resp, err := v.client.do("GET", "/public/time", []byte(""), false)
+	return v.GetServerTimeCtx(context.Background())
+}
+
+func (v *Valr) GetServerTimeCtx(ctx context.Context) (serverTime *ServerTime, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/public/time", []byte(""), false)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,209 @@
+package valr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned instead of waiting when a RateLimiter runs in RateLimitFailFast mode
+var ErrRateLimited = errors.New("valr: client side rate limit exceeded")
+
+// EndpointGroup groups Valr endpoints that share a rate limit bucket
+type EndpointGroup string
+
+const (
+	PublicEndpoints  EndpointGroup = "public"
+	AccountEndpoints EndpointGroup = "account"
+	OrderEndpoints   EndpointGroup = "orders"
+	WalletEndpoints  EndpointGroup = "wallet"
+)
+
+// endpointGroup maps a request path such as "/orders/limit" to its bucket
+func endpointGroup(path string) EndpointGroup {
+	segment := strings.Trim(path, "/")
+	if i := strings.IndexAny(segment, "/?"); i >= 0 {
+		segment = segment[:i]
+	}
+	switch segment {
+	case "public":
+		return PublicEndpoints
+	case "orders", "simple", "batch":
+		return OrderEndpoints
+	case "wallet":
+		return WalletEndpoints
+	default:
+		return AccountEndpoints
+	}
+}
+
+// RateLimit is a token bucket: Rate tokens per second with room for Burst requests
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultRateLimits returns conservative limits that keep a single api key
+// well inside Valr's published per key and per IP limits
+func DefaultRateLimits() map[EndpointGroup]RateLimit {
+	return map[EndpointGroup]RateLimit{
+		PublicEndpoints:  {Rate: 10, Burst: 10},
+		AccountEndpoints: {Rate: 10, Burst: 20},
+		OrderEndpoints:   {Rate: 20, Burst: 40},
+		WalletEndpoints:  {Rate: 5, Burst: 5},
+	}
+}
+
+type RateLimitMode int
+
+const (
+	// RateLimitBlock waits until a token is available or the context is done
+	RateLimitBlock RateLimitMode = iota
+	// RateLimitFailFast returns ErrRateLimited when no token is available
+	RateLimitFailFast
+)
+
+// BucketState is a point in time view of one bucket, suitable for dashboards
+type BucketState struct {
+	Group       EndpointGroup
+	Limit       RateLimit
+	Tokens      float64
+	PausedUntil time.Time
+	Throttled   uint64
+}
+
+type bucket struct {
+	limit       RateLimit
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	throttled   uint64
+}
+
+func (b *bucket) refill(now time.Time) {
+	if now.Before(b.last) {
+		return
+	}
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if b.tokens > float64(b.limit.Burst) {
+		b.tokens = float64(b.limit.Burst)
+	}
+	b.last = now
+}
+
+// reserve takes a token if one is available, otherwise it returns how long to wait for one
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+// RateLimiter throttles requests per EndpointGroup. It is safe for concurrent use
+// and is meant to be shared by every Valr instance using the same api key.
+type RateLimiter struct {
+	mode    RateLimitMode
+	mu      sync.Mutex
+	buckets map[EndpointGroup]*bucket
+}
+
+// NewRateLimiter returns a RateLimiter with a bucket per group in limits,
+// groups without an entry are not throttled
+func NewRateLimiter(limits map[EndpointGroup]RateLimit, mode RateLimitMode) *RateLimiter {
+	now := time.Now()
+	buckets := make(map[EndpointGroup]*bucket, len(limits))
+	for group, limit := range limits {
+		if limit.Rate <= 0 {
+			continue
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		buckets[group] = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+	}
+	return &RateLimiter{mode: mode, buckets: buckets}
+}
+
+// Wait takes a token from the group's bucket, blocking or failing fast depending on the mode
+func (l *RateLimiter) Wait(ctx context.Context, group EndpointGroup) error {
+	for {
+		l.mu.Lock()
+		b, ok := l.buckets[group]
+		if !ok {
+			l.mu.Unlock()
+			return nil
+		}
+		wait := b.reserve(time.Now())
+		l.mu.Unlock()
+
+		if wait <= 0 {
+			return nil
+		}
+		if l.mode == RateLimitFailFast {
+			return ErrRateLimited
+		}
+
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Pause stops handing out tokens for the group until d has passed
+func (l *RateLimiter) Pause(group EndpointGroup, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[group]
+	if !ok {
+		return
+	}
+	b.throttled++
+	until := time.Now().Add(d)
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+		b.last = until
+	}
+	b.tokens = 0
+}
+
+// State returns the current state of every bucket sorted by group
+func (l *RateLimiter) State() []BucketState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	states := make([]BucketState, 0, len(l.buckets))
+	for group, b := range l.buckets {
+		b.refill(now)
+		states = append(states, BucketState{group, b.limit, b.tokens, b.pausedUntil, b.throttled})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Group < states[j].Group })
+	return states
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a http date
+func retryAfter(header http.Header, fallback time.Duration) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return fallback
+}
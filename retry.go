@@ -0,0 +1,139 @@
+package valr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried.
+// Only idempotent requests are retried: GET and DELETE requests, and order
+// placements that carry a CustomerOrderID so Valr can reject duplicates.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 1 disables retries
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomises each backoff by up to this fraction of it, between 0 and 1
+	Jitter float64
+	// Retryable decides whether a failed attempt is retried, statusCode is 0 when no response was received.
+	// DefaultRetryable is used when nil.
+	Retryable func(statusCode int, err error) bool
+}
+
+// DefaultRetryPolicy retries up to 3 attempts starting with a 200ms backoff
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// DefaultRetryable retries timeouts, connection errors, 429 and 5xx gateway/availability errors
+func DefaultRetryable(statusCode int, err error) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case 0:
+	default:
+		return false
+	}
+
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrTimeout) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection reset")
+}
+
+func (p *RetryPolicy) retryable(err error) (bool, time.Duration) {
+	statusCode := 0
+	var retryAfterHeader http.Header
+	if apiErr, ok := asAPIError(err); ok {
+		statusCode = apiErr.StatusCode
+		retryAfterHeader = apiErr.Header
+	}
+
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	if !retryable(statusCode, err) {
+		return false, 0
+	}
+	if statusCode == http.StatusTooManyRequests {
+		return true, retryAfter(retryAfterHeader, 0)
+	}
+	return true, 0
+}
+
+// backoff returns the wait before the next attempt, attempt starts at 1
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(wait)
+}
+
+// isIdempotent reports whether a request can safely be sent more than once
+func isIdempotent(method, path string, data []byte) bool {
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "DELETE":
+		return true
+	case "POST":
+		return endpointGroup(path) == OrderEndpoints && hasCustomerOrderID(data)
+	default:
+		return false
+	}
+}
+
+func hasCustomerOrderID(data []byte) bool {
+	if !bytes.Contains(data, []byte("customerOrderId")) {
+		return false
+	}
+	var body struct {
+		CustomerOrderID string `json:"customerOrderId"`
+	}
+	return json.Unmarshal(data, &body) == nil && body.CustomerOrderID != ""
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package valr
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer signs authenticated requests. Implement it to keep the api secret
+// out of process, e.g. in a signing daemon or a secrets manager.
+type Signer interface {
+	// Sign returns the hex encoded signature for a request.
+	// timestamp is in unix milliseconds, subaccountID is empty for the primary account.
+	Sign(timestamp, method, path, body, subaccountID string) (string, error)
+}
+
+// HMACSigner is the default Signer, it signs with HMAC-SHA512 over the api secret
+type HMACSigner struct {
+	secret []byte
+}
+
+func NewHMACSigner(apiSecret string) *HMACSigner {
+	return &HMACSigner{[]byte(apiSecret)}
+}
+
+func (s *HMACSigner) Sign(timestamp, method, path, body, subaccountID string) (string, error) {
+	return hex.EncodeToString(hmacSignature(s.secret, timestamp, method, path, body, subaccountID)), nil
+}
+
+func hmacSignature(secret []byte, timestamp, method, path, body, subaccountID string) []byte {
+	// Create a new Keyed-Hash Message Authentication Code (HMAC) using SHA512 and API Secret
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte(strings.ToUpper(method)))
+	mac.Write([]byte(path))
+	mac.Write([]byte(body))
+	// Requests made on behalf of a subaccount also sign its id
+	mac.Write([]byte(subaccountID))
+	return mac.Sum(nil)
+}
+
+// VerifySignature reports whether signature is the valid signature of the request for apiSecret.
+// It compares in constant time so it can back a signature checking proxy.
+func VerifySignature(apiSecret, signature, timestamp, method, path, body, subaccountID string) bool {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(decoded, hmacSignature([]byte(apiSecret), timestamp, method, path, body, subaccountID))
+}
+
+// formatTimestamp converts a time to the unix milliseconds string Valr expects
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
@@ -1,27 +1,48 @@
 package valr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type simpleBuySell struct {
-	PayInCurrency string    `json:"payInCurrency"`
-	PayAmount     float64   `json:"payAmount"`
-	Side          OrderSide `json:"side"`
+	PayInCurrency string          `json:"payInCurrency"`
+	PayAmount     decimal.Decimal `json:"payAmount"`
+	Side          OrderSide       `json:"side"`
 }
 
 type Quote struct {
 	CurrencyPair  string
-	PayAmount     float64 `json:",string"`
-	ReceiveAmount float64 `json:",string"`
-	Fee           float64 `json:",string"`
+	PayAmount     decimal.Decimal
+	ReceiveAmount decimal.Decimal
+	Fee           decimal.Decimal
 	FeeCurrency   string
-	Created       string
+	Created       time.Time
 	ID            string
 }
 
-func (v *Valr) SimpleBuyQuote(currencyPair, payInCurrency string, amount float64) (quote *Quote, err error) {
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	type quote Quote
+	aux := struct {
+		*quote
+		Created jsonTime
+	}{quote: (*quote)(q)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	q.Created = time.Time(aux.Created)
+	return nil
+}
+
+func (v *Valr) SimpleBuyQuote(currencyPair, payInCurrency string, amount decimal.Decimal) (quote *Quote, err error) {
+	return v.SimpleBuyQuoteCtx(context.Background(), currencyPair, payInCurrency, amount)
+}
+
+func (v *Valr) SimpleBuyQuoteCtx(ctx context.Context, currencyPair, payInCurrency string, amount decimal.Decimal) (quote *Quote, err error) {
 	path := fmt.Sprintf("/simple/%s/quote", currencyPair)
 	buy := simpleBuySell{payInCurrency, amount, BUY}
 
@@ -30,7 +51,7 @@ func (v *Valr) SimpleBuyQuote(currencyPair, payInCurrency string, amount float64
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
@@ -38,7 +59,11 @@ func (v *Valr) SimpleBuyQuote(currencyPair, payInCurrency string, amount float64
 	return
 }
 
-func (v *Valr) SimpleSellQuote(currencyPair, payInCurrency string, amount float64) (quote *Quote, err error) {
+func (v *Valr) SimpleSellQuote(currencyPair, payInCurrency string, amount decimal.Decimal) (quote *Quote, err error) {
+	return v.SimpleSellQuoteCtx(context.Background(), currencyPair, payInCurrency, amount)
+}
+
+func (v *Valr) SimpleSellQuoteCtx(ctx context.Context, currencyPair, payInCurrency string, amount decimal.Decimal) (quote *Quote, err error) {
 	path := fmt.Sprintf("/simple/%s/quote", currencyPair)
 	buy := simpleBuySell{payInCurrency, amount, SELL}
 
@@ -47,7 +72,7 @@ func (v *Valr) SimpleSellQuote(currencyPair, payInCurrency string, amount float6
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
@@ -57,9 +82,15 @@ func (v *Valr) SimpleSellQuote(currencyPair, payInCurrency string, amount float6
 
 type OrderID struct {
 	ID string
+	// CustomerOrderID is the customer order id the order was placed with, if any
+	CustomerOrderID string
+}
+
+func (v *Valr) SimpleBuyOrder(currencyPair, payInCurrency string, amount decimal.Decimal) (id *OrderID, err error) {
+	return v.SimpleBuyOrderCtx(context.Background(), currencyPair, payInCurrency, amount)
 }
 
-func (v *Valr) SimpleBuyOrder(currencyPair, payInCurrency string, amount float64) (id *OrderID, err error) {
+func (v *Valr) SimpleBuyOrderCtx(ctx context.Context, currencyPair, payInCurrency string, amount decimal.Decimal) (id *OrderID, err error) {
 	path := fmt.Sprintf("/simple/%s/order", currencyPair)
 	buy := simpleBuySell{payInCurrency, amount, BUY}
 
@@ -68,7 +99,7 @@ func (v *Valr) SimpleBuyOrder(currencyPair, payInCurrency string, amount float64
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
@@ -76,7 +107,11 @@ func (v *Valr) SimpleBuyOrder(currencyPair, payInCurrency string, amount float64
 	return
 }
 
-func (v *Valr) SimpleSellOrder(currencyPair, payInCurrency string, amount float64) (id *OrderID, err error) {
+func (v *Valr) SimpleSellOrder(currencyPair, payInCurrency string, amount decimal.Decimal) (id *OrderID, err error) {
+	return v.SimpleSellOrderCtx(context.Background(), currencyPair, payInCurrency, amount)
+}
+
+func (v *Valr) SimpleSellOrderCtx(ctx context.Context, currencyPair, payInCurrency string, amount decimal.Decimal) (id *OrderID, err error) {
 	path := fmt.Sprintf("/simple/%s/order", currencyPair)
 	buy := simpleBuySell{payInCurrency, amount, SELL}
 
@@ -85,7 +120,7 @@ func (v *Valr) SimpleSellOrder(currencyPair, payInCurrency string, amount float6
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,110 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// PrimaryAccountID is the id of the primary account when transferring funds
+const PrimaryAccountID = "0"
+
+// Subaccount returns a copy of v whose requests act on behalf of the subaccount with the given id.
+// Pass an empty id to act as the primary account again.
+func (v *Valr) Subaccount(id string) *Valr {
+	c := *v.client
+	c.subaccountID = id
+	scoped := *v
+	scoped.client = &c
+	return &scoped
+}
+
+// SubaccountID returns the id of the subaccount v acts for, empty for the primary account
+func (v *Valr) SubaccountID() string {
+	return v.client.subaccountID
+}
+
+type Subaccount struct {
+	Label string
+	ID    string
+}
+
+func (v *Valr) GetSubaccounts() (subaccounts []Subaccount, err error) {
+	return v.GetSubaccountsCtx(context.Background())
+}
+
+func (v *Valr) GetSubaccountsCtx(ctx context.Context) (subaccounts []Subaccount, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/account/subaccounts", []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &subaccounts)
+	return
+}
+
+type newSubaccount struct {
+	Label string `json:"label"`
+}
+
+type SubaccountID struct {
+	ID string
+}
+
+func (v *Valr) NewSubaccount(label string) (id *SubaccountID, err error) {
+	return v.NewSubaccountCtx(context.Background(), label)
+}
+
+func (v *Valr) NewSubaccountCtx(ctx context.Context, label string) (id *SubaccountID, err error) {
+	body, err := structToBytes(newSubaccount{label})
+	if err != nil {
+		return
+	}
+
+	resp, err := v.client.doCtx(ctx, "POST", "/account/subaccount", body, true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &id)
+	return
+}
+
+type SubaccountBalances struct {
+	Account  Subaccount
+	Balances []Balance
+}
+
+func (v *Valr) GetAllSubaccountBalances() (balances []SubaccountBalances, err error) {
+	return v.GetAllSubaccountBalancesCtx(context.Background())
+}
+
+func (v *Valr) GetAllSubaccountBalancesCtx(ctx context.Context) (balances []SubaccountBalances, err error) {
+	resp, err := v.client.doCtx(ctx, "GET", "/account/balances/all", []byte(""), true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(resp, &balances)
+	return
+}
+
+type subaccountTransfer struct {
+	FromID       string          `json:"fromId"`
+	ToID         string          `json:"toId"`
+	CurrencyCode string          `json:"currencyCode"`
+	Amount       decimal.Decimal `json:"amount"`
+}
+
+// TransferBetweenSubaccounts moves funds between two accounts, use PrimaryAccountID for the primary account
+func (v *Valr) TransferBetweenSubaccounts(fromID, toID, currency string, amount decimal.Decimal) (err error) {
+	return v.TransferBetweenSubaccountsCtx(context.Background(), fromID, toID, currency, amount)
+}
+
+func (v *Valr) TransferBetweenSubaccountsCtx(ctx context.Context, fromID, toID, currency string, amount decimal.Decimal) (err error) {
+	body, err := structToBytes(subaccountTransfer{fromID, toID, currency, amount})
+	if err != nil {
+		return
+	}
+
+	_, err = v.client.doCtx(ctx, "POST", "/account/subaccounts/transfer", body, true)
+	return
+}
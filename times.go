@@ -0,0 +1,58 @@
+package valr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// timeLayouts are the ISO-8601 forms Valr uses, times without a zone are UTC
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02",
+}
+
+// parseTime parses any of Valr's timestamp forms, including unix milliseconds.
+// An empty value is the zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, millis*int64(time.Millisecond)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("valr: cannot parse time %q", value)
+}
+
+// jsonTime decodes a json string or number with parseTime,
+// it is used by the UnmarshalJSON methods of types with time fields
+type jsonTime time.Time
+
+func (t *jsonTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	}
+	parsed, err := parseTime(value)
+	if err != nil {
+		return err
+	}
+	*t = jsonTime(parsed)
+	return nil
+}
@@ -0,0 +1,267 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const tradeStreamPath = "/ws/trade"
+
+type TradeEventType string
+
+const (
+	TradeAggregatedOrderBookUpdate TradeEventType = "AGGREGATED_ORDERBOOK_UPDATE"
+	TradeFullOrderBookSnapshot     TradeEventType = "FULL_ORDERBOOK_SNAPSHOT"
+	TradeFullOrderBookUpdate       TradeEventType = "FULL_ORDERBOOK_UPDATE"
+	TradeMarketSummaryUpdate       TradeEventType = "MARKET_SUMMARY_UPDATE"
+	TradeNewTrade                  TradeEventType = "NEW_TRADE"
+	TradeNewTradeBucket            TradeEventType = "NEW_TRADE_BUCKET"
+	TradeSubscribed                TradeEventType = "SUBSCRIBED"
+)
+
+// LevelOrder is a single order resting at a price level of a full order book
+type LevelOrder struct {
+	OrderID  string
+	Quantity decimal.Decimal
+}
+
+// PriceLevel is a price of a full order book with every order resting at it
+type PriceLevel struct {
+	Price  decimal.Decimal
+	Orders []LevelOrder
+}
+
+// FullOrderBookUpdate is a snapshot, or the price levels that changed since the previous
+// SequenceNumber; a level without orders has been removed
+type FullOrderBookUpdate struct {
+	Asks           []PriceLevel
+	Bids           []PriceLevel
+	LastChange     time.Time
+	SequenceNumber uint64
+	Checksum       int64
+}
+
+func (f *FullOrderBookUpdate) UnmarshalJSON(data []byte) error {
+	type fullOrderBookUpdate FullOrderBookUpdate
+	aux := struct {
+		*fullOrderBookUpdate
+		LastChange jsonTime
+	}{fullOrderBookUpdate: (*fullOrderBookUpdate)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.LastChange = time.Time(aux.LastChange)
+	return nil
+}
+
+// TradeBucket is an OHLC candle of a pair's trades
+type TradeBucket struct {
+	CurrencyPairSymbol    string
+	BucketPeriodInSeconds int
+	StartTime             time.Time
+	Open                  decimal.Decimal
+	High                  decimal.Decimal
+	Low                   decimal.Decimal
+	Close                 decimal.Decimal
+	Volume                decimal.Decimal
+}
+
+func (b *TradeBucket) UnmarshalJSON(data []byte) error {
+	type tradeBucket TradeBucket
+	aux := struct {
+		*tradeBucket
+		StartTime jsonTime
+	}{tradeBucket: (*tradeBucket)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.StartTime = time.Time(aux.StartTime)
+	return nil
+}
+
+// TradeEvent is a message from the trade stream, only the field matching Type is set.
+// Data holds the raw payload, also for types this package does not decode.
+type TradeEvent struct {
+	Type          TradeEventType
+	Pair          string
+	OrderBook     *OrderBook
+	FullOrderBook *FullOrderBookUpdate
+	MarketSummary *MarketSummary
+	Trade         *Trade
+	TradeBucket   *TradeBucket
+	Data          json.RawMessage
+}
+
+func decodeTradeEvent(msg *wsMessage) (event TradeEvent, err error) {
+	event = TradeEvent{Type: TradeEventType(msg.Type), Pair: msg.CurrencyPairSymbol, Data: msg.Data}
+	if len(msg.Data) == 0 {
+		return
+	}
+
+	switch event.Type {
+	case TradeAggregatedOrderBookUpdate:
+		err = json.Unmarshal(msg.Data, &event.OrderBook)
+	case TradeFullOrderBookSnapshot, TradeFullOrderBookUpdate:
+		err = json.Unmarshal(msg.Data, &event.FullOrderBook)
+	case TradeMarketSummaryUpdate:
+		if err = json.Unmarshal(msg.Data, &event.MarketSummary); err == nil && event.MarketSummary.CurrencyPair == "" {
+			event.MarketSummary.CurrencyPair = event.Pair
+		}
+	case TradeNewTrade:
+		if err = json.Unmarshal(msg.Data, &event.Trade); err == nil && event.Trade.CurrencyPair == "" {
+			event.Trade.CurrencyPair = event.Pair
+		}
+	case TradeNewTradeBucket:
+		err = json.Unmarshal(msg.Data, &event.TradeBucket)
+	}
+	if event.Pair == "" {
+		switch {
+		case event.MarketSummary != nil:
+			event.Pair = event.MarketSummary.CurrencyPair
+		case event.TradeBucket != nil:
+			event.Pair = event.TradeBucket.CurrencyPairSymbol
+		}
+	}
+	return
+}
+
+type subscription struct {
+	Event TradeEventType `json:"event"`
+	Pairs []string       `json:"pairs"`
+}
+
+type subscribeMessage struct {
+	Type          string         `json:"type"`
+	Subscriptions []subscription `json:"subscriptions"`
+}
+
+// TradeStream delivers market data of Valr's /ws/trade stream for the subscribed pairs.
+// After a reconnection every subscription is sent again, see States.
+type TradeStream struct {
+	*wsStream
+	events chan TradeEvent
+
+	subMu         sync.Mutex
+	subscriptions map[TradeEventType]map[string]bool
+}
+
+// NewTradeStream connects to the trade stream, call Subscribe to start receiving events
+func (v *Valr) NewTradeStream(ctx context.Context) (*TradeStream, error) {
+	s := &TradeStream{
+		events:        make(chan TradeEvent, 1024),
+		subscriptions: make(map[TradeEventType]map[string]bool),
+	}
+	stream, err := v.client.openStream(ctx, tradeStreamPath, s.handle, func() { close(s.events) })
+	if err != nil {
+		return nil, err
+	}
+	stream.resync = s.replaySubscriptions
+	s.wsStream = stream
+	stream.start()
+	return s, nil
+}
+
+// Events returns the channel events are delivered on, it is closed when the stream ends.
+// Err tells why it ended.
+func (s *TradeStream) Events() <-chan TradeEvent {
+	return s.events
+}
+
+// Subscribe adds pairs to the ones event is received for
+func (s *TradeStream) Subscribe(event TradeEventType, pairs ...string) error {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	subscribed, ok := s.subscriptions[event]
+	if !ok {
+		subscribed = make(map[string]bool)
+		s.subscriptions[event] = subscribed
+	}
+	for _, pair := range pairs {
+		subscribed[strings.ToUpper(pair)] = true
+	}
+	return s.sendSubscription(event)
+}
+
+// Unsubscribe removes pairs from the ones event is received for, no pairs removes them all
+func (s *TradeStream) Unsubscribe(event TradeEventType, pairs ...string) error {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	subscribed := s.subscriptions[event]
+	if len(pairs) == 0 {
+		subscribed = nil
+	}
+	for _, pair := range pairs {
+		delete(subscribed, strings.ToUpper(pair))
+	}
+	if len(subscribed) == 0 {
+		delete(s.subscriptions, event)
+	}
+	return s.sendSubscription(event)
+}
+
+// Subscriptions returns the subscribed pairs per event
+func (s *TradeStream) Subscriptions() map[TradeEventType][]string {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	subscriptions := make(map[TradeEventType][]string, len(s.subscriptions))
+	for event := range s.subscriptions {
+		subscriptions[event] = s.subscribedPairs(event)
+	}
+	return subscriptions
+}
+
+func (s *TradeStream) subscribedPairs(event TradeEventType) []string {
+	pairs := make([]string, 0, len(s.subscriptions[event]))
+	for pair := range s.subscriptions[event] {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// sendSubscription sends the full pair set of event, Valr replaces the previous set with it
+// and an empty set unsubscribes from the event. While reconnecting the set is only recorded,
+// it is replayed once connected again.
+func (s *TradeStream) sendSubscription(event TradeEventType) error {
+	err := s.send(subscribeMessage{
+		Type:          "SUBSCRIBE",
+		Subscriptions: []subscription{{event, s.subscribedPairs(event)}},
+	})
+	if err == ErrNotConnected {
+		return nil
+	}
+	return err
+}
+
+// replaySubscriptions sends every subscription again after a reconnection
+func (s *TradeStream) replaySubscriptions() error {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	if len(s.subscriptions) == 0 {
+		return nil
+	}
+	msg := subscribeMessage{Type: "SUBSCRIBE"}
+	for event := range s.subscriptions {
+		msg.Subscriptions = append(msg.Subscriptions, subscription{event, s.subscribedPairs(event)})
+	}
+	return s.send(msg)
+}
+
+func (s *TradeStream) handle(msg *wsMessage) bool {
+	event, err := decodeTradeEvent(msg)
+	if err != nil {
+		s.client.logger.Warn("valr trade event not decoded", "type", msg.Type, "error", err)
+	}
+	select {
+	case s.events <- event:
+		return true
+	case <-s.done:
+		return false
+	}
+}
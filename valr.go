@@ -2,7 +2,9 @@ package valr
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -10,54 +12,91 @@ import (
 const (
 	HttpBase   = "https://api.valr.com"
 	ApiVersion = "v1"
-	WsBase     = ""
+	WsBase     = "wss://api.valr.com"
 )
 
 type Valr struct {
 	client *client
+	// pairs validates orders before they are placed when set
+	pairs *PairRegistry
+	// autoCustomerOrderID generates the customer order id of orders placed without one
+	autoCustomerOrderID bool
 }
 
 type OrderSide string
 
 const (
 	SELL OrderSide = "sell"
-	BUY            = "buy"
+	BUY  OrderSide = "buy"
 )
 
 // New returns an instantiated Valr struct
 func New(apiKey, apiSecret string) *Valr {
-	client := NewClient(apiKey, apiSecret)
-	return &Valr{client}
+	return NewClient(WithCredentials(apiKey, apiSecret))
 }
 
 // NewWithCustomHttpClient returns an instantiated Valr struct with custom http client
 func NewWithCustomHttpClient(apiKey, apiSecret string, httpClient *http.Client) *Valr {
-	client := NewClientWithCustomHttpConfig(apiKey, apiSecret, httpClient)
-	return &Valr{client}
+	return NewClient(WithCredentials(apiKey, apiSecret), WithHTTPClient(httpClient))
 }
 
 // NewWithCustomTimeout returns an instantiated Valr struct with custom timeout
 func NewWithCustomTimeout(apiKey, apiSecret string, timeout time.Duration) *Valr {
-	client := NewClientWithCustomTimeout(apiKey, apiSecret, timeout)
-	return &Valr{client}
+	return NewClient(WithCredentials(apiKey, apiSecret), WithTimeout(timeout))
 }
 
 // set enable/disable http request/response dump
+//
+// Deprecated: use NewClient with WithDebug.
 func (v *Valr) SetDebug(enable bool) {
-	v.client.debug = enable
+	v.client.setDebug(enable)
 }
 
+// Deprecated: use NewClient with WithHTTPBase.
 func (v *Valr) SetHttpBase(base string) {
 	v.client.setHttpBase(base)
 }
 
+// Deprecated: use NewClient with WithWsBase.
 func (v *Valr) SetWsBase(base string) {
 	v.client.setWsBase(base)
 }
+
+// Deprecated: use NewClient with WithAPIVersion.
 func (v *Valr) SetApiVersion(version string) {
 	v.client.setApiVersion(version)
 }
 
+// SetRateLimiter throttles every request through limiter, nil disables client side throttling.
+// Share one limiter between all Valr instances using the same api key.
+//
+// Deprecated: use NewClient with WithRateLimiter.
+func (v *Valr) SetRateLimiter(limiter *RateLimiter) {
+	v.client.setRateLimiter(limiter)
+}
+
+// SetSigner replaces the HMAC signer built from the api secret, e.g. with one backed by a signing service
+//
+// Deprecated: use NewClient with WithSigner.
+func (v *Valr) SetSigner(signer Signer) {
+	v.client.setSigner(signer)
+}
+
+// SetRetryPolicy replaces the retry policy applied to idempotent requests, nil disables retries
+//
+// Deprecated: use NewClient with WithRetryPolicy.
+func (v *Valr) SetRetryPolicy(policy *RetryPolicy) {
+	v.client.setRetryPolicy(policy)
+}
+
+// RateLimitState returns the state of every rate limit bucket, nil when no limiter is set
+func (v *Valr) RateLimitState() []BucketState {
+	if v.client.limiter == nil {
+		return nil
+	}
+	return v.client.limiter.State()
+}
+
 func structToBytes(val interface{}) ([]byte, error) {
 	bytesBuffer := new(bytes.Buffer)
 	if err := json.NewEncoder(bytesBuffer).Encode(val); err != nil {
@@ -66,3 +105,14 @@ func structToBytes(val interface{}) ([]byte, error) {
 
 	return bytesBuffer.Bytes(), nil
 }
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -1,13 +1,33 @@
 package valr
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+func TestValrSignature(t *testing.T) {
+	// Example request from the Valr api documentation
+	apiSecret := "4961b74efac86b25cce8fbe4c9811c4c7a787b7a5996660afcc2e287ad864363"
+	expected := "9d52c181ed69460b49307b7891f04658e938b21181173844b5018b2fe783a6d4c62b8e67a03de4d099e7437ebfabe12c56233b73c6a0cc0f7ae87e05f6289928"
+
+	signature, err := NewHMACSigner(apiSecret).Sign("1558014486185", "GET", "/v1/account/balances", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, signature)
+	assert.True(t, VerifySignature(apiSecret, signature, "1558014486185", "GET", "/v1/account/balances", "", ""))
+	assert.False(t, VerifySignature(apiSecret, signature, "1558014486186", "GET", "/v1/account/balances", "", ""))
+}
+
 func TestValrHttpPublicApi(t *testing.T) {
 	if err := godotenv.Load(); err != nil {
 		log.Fatal(err)
@@ -61,10 +81,27 @@ func TestValrHttpPublicApi(t *testing.T) {
 	marketSummaryInvalid, err := valr.GetMarketSummaryForCurrencyPair("BTCZA")
 	assert.NotNil(t, err)
 	assert.Nil(t, marketSummaryInvalid)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "GET", apiErr.Method)
 
 	serverTime, err := valr.GetServerTime()
 	assert.Nil(t, err)
 	assert.NotNil(t, serverTime)
+
+	registry := NewPairRegistry(valr, time.Hour)
+	pair, err := registry.Pair(context.Background(), "btczar")
+	assert.Nil(t, err)
+	assert.Equal(t, "BTCZAR", pair.Symbol)
+	var validationErr *OrderValidationError
+	err = registry.ValidateLimitOrder(context.Background(), LimitOrder{Side: BUY, Pair: "BTCZAR", Price: pair.TickSize})
+	assert.True(t, errors.As(err, &validationErr))
+	_, err = registry.Pair(context.Background(), "BTCZA")
+	assert.True(t, errors.Is(err, ErrUnknownPair))
+
+	offset, err := valr.SyncClock(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, offset, valr.ClockOffset())
 }
 
 func TestValrHttpAccountApi(t *testing.T) {
@@ -116,6 +153,16 @@ func TestValrHttpAccountApi(t *testing.T) {
 	transactionHistoryCurrencyPair, err := valr.GetTransactionHistoryForCurrencyPair("BTCZAR", 1)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(transactionHistoryCurrencyPair))
+
+	subaccounts, err := valr.GetSubaccounts()
+	assert.Nil(t, err)
+	allBalances, err := valr.GetAllSubaccountBalances()
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, len(allBalances), 1)
+	if len(subaccounts) > 0 {
+		_, err = valr.Subaccount(subaccounts[0].ID).GetBalance()
+		assert.Nil(t, err)
+	}
 }
 
 func TestValrHttpWalletApi(t *testing.T) {
@@ -154,7 +201,7 @@ func TestValrHttpWalletApi(t *testing.T) {
 	assert.NotNil(t, status)
 	assert.Equal(t, "XRP", status.Currency)
 	assert.Equal(t, depositAddress.Address, status.Address)
-	assert.Equal(t, withdrawalInfo.MinimumWithdrawAmount, status.Amount)
+	assert.True(t, withdrawalInfo.MinimumWithdrawAmount.Equal(status.Amount))
 
 	depositHistory, err := valr.GetCryptoDepositHistory("BTC", 0, 2)
 	assert.Nil(t, err)
@@ -166,13 +213,13 @@ func TestValrHttpWalletApi(t *testing.T) {
 	assert.GreaterOrEqual(t, len(withdrawHistory), 1)
 	assert.Equal(t, "XRP", withdrawHistory[0].Currency)
 	assert.Equal(t, depositAddress.Address, withdrawHistory[0].Address)
-	assert.Equal(t, withdrawalInfo.MinimumWithdrawAmount, withdrawHistory[0].Amount)
+	assert.True(t, withdrawalInfo.MinimumWithdrawAmount.Equal(withdrawHistory[0].Amount))
 
 	accounts, err := valr.GetBankAccounts()
 	assert.Nil(t, err)
 	assert.GreaterOrEqual(t, len(accounts), 1)
 
-	fiatWithdraw, err := valr.NewFiatWithdrawal(accounts[0].ID, 1.0, false)
+	fiatWithdraw, err := valr.NewFiatWithdrawal(accounts[0].ID, decimal.NewFromInt(1), false)
 	assert.Nil(t, err)
 	assert.NotNil(t, fiatWithdraw)
 	assert.NotEqual(t, "", fiatWithdraw.ID)
@@ -214,7 +261,8 @@ func TestValrHttpMarketApi(t *testing.T) {
 	assert.Nil(t, err)
 	assert.GreaterOrEqual(t, len(tradeHistory), 1)
 	assert.Equal(t, "BTCZAR", tradeHistory[0].CurrencyPair)
-	assert.NotEqual(t, 0.0, tradeHistory[0].Price)
+	assert.False(t, tradeHistory[0].Price.IsZero())
+	assert.False(t, tradeHistory[0].TradeAt.IsZero())
 }
 
 func TestValrHttpSimpleApi(t *testing.T) {
@@ -231,22 +279,22 @@ func TestValrHttpSimpleApi(t *testing.T) {
 		valr.SetHttpBase(httpBase)
 	}
 
-	buyQuote, err := valr.SimpleBuyQuote("BTCZAR", "ZAR", 10)
+	buyQuote, err := valr.SimpleBuyQuote("BTCZAR", "ZAR", decimal.NewFromInt(10))
 	assert.Nil(t, err)
 	assert.NotNil(t, buyQuote)
 	assert.Equal(t, "BTCZAR", buyQuote.CurrencyPair)
 
-	sellQuote, err := valr.SimpleSellQuote("BTCZAR", "BTC", 0.0001)
+	sellQuote, err := valr.SimpleSellQuote("BTCZAR", "BTC", decimal.RequireFromString("0.0001"))
 	assert.Nil(t, err)
 	assert.NotNil(t, sellQuote)
 	assert.Equal(t, "BTCZAR", sellQuote.CurrencyPair)
 
-	buyOrder, err := valr.SimpleBuyOrder("XRPZAR", "ZAR", 10)
+	buyOrder, err := valr.SimpleBuyOrder("XRPZAR", "ZAR", decimal.NewFromInt(10))
 	assert.Nil(t, err)
 	assert.NotNil(t, buyOrder)
 	assert.NotEqual(t, "", buyOrder.ID)
 
-	sellOrder, err := valr.SimpleSellOrder("XRPZAR", "XRP", 3)
+	sellOrder, err := valr.SimpleSellOrder("XRPZAR", "XRP", decimal.NewFromInt(3))
 	assert.Nil(t, err)
 	assert.NotNil(t, sellOrder)
 	assert.NotEmpty(t, sellOrder.ID)
@@ -266,21 +314,23 @@ func TestValrHttpExchangeApi(t *testing.T) {
 		valr.SetHttpBase(httpBase)
 	}
 
+	customerOrderID := NewCustomerOrderID()
 	id, err := valr.PlaceLimitOrder(LimitOrder{
 		Side:            "BUY",
-		Quantity:        10,
-		Price:           130120,
+		Quantity:        decimal.NewFromInt(10),
+		Price:           decimal.NewFromInt(130120),
 		Pair:            "BTCZAR",
 		PostOnly:        false,
-		CustomerOrderID: "",
+		CustomerOrderID: customerOrderID,
 	})
 	assert.Nil(t, err)
 	assert.NotNil(t, id)
 	assert.NotEmpty(t, id.ID)
+	assert.Equal(t, customerOrderID, id.CustomerOrderID)
 
 	id2, err := valr.PlaceMarketOrder(MarketOrder{
 		Side:            "SELL",
-		BaseAmount:      0.0001,
+		BaseAmount:      decimal.RequireFromString("0.0001"),
 		Pair:            "BTCZAR",
 		CustomerOrderID: "",
 	})
@@ -292,4 +342,228 @@ func TestValrHttpExchangeApi(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, status)
 	assert.Equal(t, id.ID, status.OrderID)
+
+	status, err = valr.GetOrderStatusByCustomerID("BTCZAR", customerOrderID)
+	assert.Nil(t, err)
+	assert.NotNil(t, status)
+	assert.Equal(t, id.ID, status.OrderID)
+
+	newPrice := decimal.NewFromInt(130100)
+	status, err = valr.ModifyOrder(OrderModification{
+		OrderID:  id.ID,
+		Pair:     "BTCZAR",
+		NewPrice: &newPrice,
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, status)
+	assert.Equal(t, id.ID, status.OrderID)
+	assert.True(t, newPrice.Equal(status.OriginalPrice))
+
+	openOrders, err := valr.GetOpenOrders()
+	assert.Nil(t, err)
+	assert.NotNil(t, openOrders)
+
+	assert.Nil(t, valr.CancelOrder("BTCZAR", id.ID))
+
+	stopID, err := valr.PlaceStopLimitOrder(StopLimitOrder{
+		Side:        SELL,
+		Quantity:    decimal.RequireFromString("0.0001"),
+		Price:       decimal.NewFromInt(100000),
+		StopPrice:   decimal.NewFromInt(101000),
+		Pair:        "BTCZAR",
+		Type:        StopLossLimit,
+		TimeInForce: GoodTillCancelled,
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, stopID)
+	assert.NotEmpty(t, stopID.ID)
+
+	result, err := valr.Batch().
+		PlaceLimitOrder(LimitOrder{
+			Side:     BUY,
+			Quantity: decimal.RequireFromString("0.0001"),
+			Price:    decimal.NewFromInt(100000),
+			Pair:     "BTCZAR",
+			PostOnly: true,
+		}).
+		CancelOrder("BTCZAR", stopID.ID).
+		Send()
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.Len(t, result.Outcomes, 2)
+	assert.Equal(t, BatchPlaceLimit, result.Outcomes[0].Type)
+	assert.True(t, result.Outcomes[0].Accepted)
+	assert.NotEmpty(t, result.Outcomes[0].OrderID)
+
+	cancelled, err := valr.CancelAllOrdersForPair("BTCZAR")
+	assert.Nil(t, err)
+	for _, order := range cancelled {
+		assert.Equal(t, "BTCZAR", order.CurrencyPair)
+	}
+
+	history, err := valr.GetOrderHistory(0, 10)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, history)
+
+	summary, err := valr.GetOrderHistorySummary(id2.ID)
+	assert.Nil(t, err)
+	assert.NotNil(t, summary)
+	assert.Equal(t, id2.ID, summary.OrderID)
+
+	events, err := valr.GetOrderHistoryDetail(id2.ID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, events)
+}
+
+func TestValrWsAccountApi(t *testing.T) {
+	if err := godotenv.Load(); err != nil {
+		log.Fatal(err)
+	}
+
+	apiKey := os.Getenv("VALR_API_KEY")
+	apiSecret := os.Getenv("VALR_API_SECRET")
+
+	valr := New(apiKey, apiSecret)
+
+	stream, err := valr.NewAccountStream(context.Background())
+	assert.Nil(t, err)
+	assert.NotNil(t, stream)
+
+	event := <-stream.Events()
+	assert.Equal(t, AccountAuthenticated, event.Type)
+
+	id, err := stream.PlaceLimitOrderWS(LimitOrder{
+		Side:     BUY,
+		Quantity: decimal.RequireFromString("0.0001"),
+		Price:    decimal.NewFromInt(10000),
+		Pair:     "BTCZAR",
+		PostOnly: true,
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, id)
+	assert.NotEmpty(t, id.ID)
+	assert.Nil(t, stream.CancelOrderWS("BTCZAR", id.ID))
+	assert.Nil(t, stream.Close())
+}
+
+func TestValrWsTradeApi(t *testing.T) {
+	if err := godotenv.Load(); err != nil {
+		log.Fatal(err)
+	}
+
+	apiKey := os.Getenv("VALR_API_KEY")
+	apiSecret := os.Getenv("VALR_API_SECRET")
+
+	valr := New(apiKey, apiSecret)
+
+	stream, err := valr.NewTradeStream(context.Background())
+	assert.Nil(t, err)
+	assert.NotNil(t, stream)
+
+	assert.Nil(t, stream.Subscribe(TradeMarketSummaryUpdate, "BTCZAR"))
+	for event := range stream.Events() {
+		if event.Type == TradeMarketSummaryUpdate {
+			assert.Equal(t, "BTCZAR", event.Pair)
+			assert.NotNil(t, event.MarketSummary)
+			break
+		}
+	}
+	assert.Nil(t, stream.Close())
+}
+
+func TestValrOrderBookManager(t *testing.T) {
+	var restCalls, restSequence int32 = 0, 20
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&restCalls, 1)
+		assert.Equal(t, "/v1/marketdata/BTCZAR/orderbook/full", r.URL.Path)
+		fmt.Fprintf(w, `{"Asks":[{"side":"sell","quantity":"1","price":"105","currencyPair":"BTCZAR","id":"a5"}],`+
+			`"Bids":[{"side":"buy","quantity":"2","price":"95","currencyPair":"BTCZAR","id":"b5"}],`+
+			`"LastChange":"2020-01-01T00:00:00.000Z","SequenceNumber":%d}`, atomic.LoadInt32(&restSequence))
+	}))
+	defer server.Close()
+
+	level := func(price int64, quantities ...int64) PriceLevel {
+		level := PriceLevel{Price: decimal.NewFromInt(price)}
+		for _, quantity := range quantities {
+			level.Orders = append(level.Orders, LevelOrder{Quantity: decimal.NewFromInt(quantity)})
+		}
+		return level
+	}
+	prices := func(levels []BookLevel) (prices []int64) {
+		for _, level := range levels {
+			prices = append(prices, level.Price.IntPart())
+		}
+		return
+	}
+
+	manager := &OrderBookManager{
+		valr:    NewClient(WithHTTPBase(server.URL), WithRetryPolicy(nil)),
+		changes: make(chan BookChange, 16),
+		ctx:     context.Background(),
+		books:   map[string]*localBook{"BTCZAR": newLocalBook()},
+	}
+
+	manager.resetBook("BTCZAR", &FullOrderBookUpdate{
+		Asks:           []PriceLevel{level(101, 1), level(103, 1)},
+		Bids:           []PriceLevel{level(99, 1), level(97, 1)},
+		SequenceNumber: 10,
+	})
+	assert.Equal(t, BookChange{"BTCZAR", 10, true}, <-manager.Changes())
+
+	// An insert between two levels and a removed level
+	manager.applyUpdate("BTCZAR", &FullOrderBookUpdate{
+		Asks:           []PriceLevel{level(102, 1, 2)},
+		Bids:           []PriceLevel{level(99)},
+		SequenceNumber: 11,
+	})
+	assert.Equal(t, BookChange{"BTCZAR", 11, false}, <-manager.Changes())
+	bids, asks, ok := manager.Depth("BTCZAR", 0)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{101, 102, 103}, prices(asks))
+	assert.Equal(t, []int64{97}, prices(bids))
+	assert.True(t, asks[1].Quantity.Equal(decimal.NewFromInt(3)))
+
+	// A sequence gap rebuilds the book from the REST order book
+	manager.applyUpdate("BTCZAR", &FullOrderBookUpdate{Asks: []PriceLevel{level(104, 1)}, SequenceNumber: 13})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&restCalls))
+	assert.Equal(t, BookChange{"BTCZAR", 20, true}, <-manager.Changes())
+	bids, asks, _ = manager.Depth("BTCZAR", 0)
+	assert.Equal(t, []int64{105}, prices(asks))
+	assert.Equal(t, []int64{95}, prices(bids))
+
+	// Updates the REST snapshot already contains are ignored
+	manager.applyUpdate("BTCZAR", &FullOrderBookUpdate{Asks: []PriceLevel{level(104, 1)}, SequenceNumber: 15})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&restCalls))
+	sequence, _ := manager.SequenceNumber("BTCZAR")
+	assert.Equal(t, uint64(20), sequence)
+	_, asks, _ = manager.Depth("BTCZAR", 0)
+	assert.Equal(t, []int64{105}, prices(asks))
+
+	manager.applyUpdate("BTCZAR", &FullOrderBookUpdate{Asks: []PriceLevel{level(104, 1)}, SequenceNumber: 21})
+	assert.Equal(t, BookChange{"BTCZAR", 21, false}, <-manager.Changes())
+	best, ok := manager.BestAsk("BTCZAR")
+	assert.True(t, ok)
+	assert.True(t, best.Price.Equal(decimal.NewFromInt(104)))
+
+	// A second gap within minResyncInterval of the last resync stops serving the book
+	// until the throttled resync rebuilds it
+	atomic.StoreInt32(&restSequence, 30)
+	manager.applyUpdate("BTCZAR", &FullOrderBookUpdate{Asks: []PriceLevel{level(106, 1)}, SequenceNumber: 25})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&restCalls))
+	_, ok = manager.BestAsk("BTCZAR")
+	assert.False(t, ok)
+	_, _, ok = manager.Depth("BTCZAR", 0)
+	assert.False(t, ok)
+	manager.applyUpdate("BTCZAR", &FullOrderBookUpdate{Asks: []PriceLevel{level(107, 1)}, SequenceNumber: 27})
+
+	select {
+	case change := <-manager.Changes():
+		assert.Equal(t, BookChange{"BTCZAR", 30, true}, change)
+	case <-time.After(3 * minResyncInterval):
+		t.Fatal("throttled resync did not run")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&restCalls))
+	sequence, ok = manager.SequenceNumber("BTCZAR")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(30), sequence)
 }
@@ -1,9 +1,12 @@
 package valr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type DepositAddress struct {
@@ -14,8 +17,12 @@ type DepositAddress struct {
 }
 
 func (v *Valr) GetDepositAddress(currencyCode string) (address *DepositAddress, err error) {
+	return v.GetDepositAddressCtx(context.Background(), currencyCode)
+}
+
+func (v *Valr) GetDepositAddressCtx(ctx context.Context, currencyCode string) (address *DepositAddress, err error) {
 	path := fmt.Sprintf("/wallet/crypto/%s/deposit/address", currencyCode)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -25,16 +32,20 @@ func (v *Valr) GetDepositAddress(currencyCode string) (address *DepositAddress,
 
 type CurrencyInfo struct {
 	Currency                string
-	MinimumWithdrawAmount   float64 `json:",string"`
+	MinimumWithdrawAmount   decimal.Decimal
 	WithdrawalDecimalPlaces float64 `json:",string"`
 	IsActive                bool
-	WithdrawCost            float64 `json:",string"`
+	WithdrawCost            decimal.Decimal
 	SupportPaymentReference bool
 }
 
 func (v *Valr) GetCurrencyWithdrawalInfo(currencyCode string) (info *CurrencyInfo, err error) {
+	return v.GetCurrencyWithdrawalInfoCtx(context.Background(), currencyCode)
+}
+
+func (v *Valr) GetCurrencyWithdrawalInfoCtx(ctx context.Context, currencyCode string) (info *CurrencyInfo, err error) {
 	path := fmt.Sprintf("/wallet/crypto/%s/withdraw", currencyCode)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -43,16 +54,20 @@ func (v *Valr) GetCurrencyWithdrawalInfo(currencyCode string) (info *CurrencyInf
 }
 
 type newWithdrawal struct {
-	Amount           float64 `json:"amount"`
-	Address          string  `json:"address"`
-	PaymentReference string  `json:"paymentReference"`
+	Amount           decimal.Decimal `json:"amount"`
+	Address          string          `json:"address"`
+	PaymentReference string          `json:"paymentReference"`
 }
 
 type WithdrawalID struct {
 	ID string
 }
 
-func (v *Valr) NewCryptoWithdrawal(currency, address string, amount float64, paymentReference string) (id *WithdrawalID, err error) {
+func (v *Valr) NewCryptoWithdrawal(currency, address string, amount decimal.Decimal, paymentReference string) (id *WithdrawalID, err error) {
+	return v.NewCryptoWithdrawalCtx(context.Background(), currency, address, amount, paymentReference)
+}
+
+func (v *Valr) NewCryptoWithdrawalCtx(ctx context.Context, currency, address string, amount decimal.Decimal, paymentReference string) (id *WithdrawalID, err error) {
 	path := fmt.Sprintf("/wallet/crypto/%s/withdraw", currency)
 	withdraw := newWithdrawal{amount, address, paymentReference}
 
@@ -61,7 +76,7 @@ func (v *Valr) NewCryptoWithdrawal(currency, address string, amount float64, pay
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
@@ -72,20 +87,39 @@ func (v *Valr) NewCryptoWithdrawal(currency, address string, amount float64, pay
 type WithdrawalStatus struct {
 	Currency           string
 	Address            string
-	Amount             float64 `json:",string"`
-	FeeAmount          float64 `json:",string"`
+	Amount             decimal.Decimal
+	FeeAmount          decimal.Decimal
 	TransactionHash    string
 	Confirmations      uint8
-	LastConfirmationAt string
+	LastConfirmationAt time.Time
 	UniqueID           string
-	CreatedAt          string
+	CreatedAt          time.Time
 	Verified           bool
-	Status             string
+	Status             WithdrawalState
+}
+
+func (w *WithdrawalStatus) UnmarshalJSON(data []byte) error {
+	type withdrawalStatus WithdrawalStatus
+	aux := struct {
+		*withdrawalStatus
+		LastConfirmationAt jsonTime
+		CreatedAt          jsonTime
+	}{withdrawalStatus: (*withdrawalStatus)(w)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	w.LastConfirmationAt = time.Time(aux.LastConfirmationAt)
+	w.CreatedAt = time.Time(aux.CreatedAt)
+	return nil
 }
 
 func (v *Valr) GetCryptoWithdrawalStatus(currency, WithdrawalID string) (status *WithdrawalStatus, err error) {
+	return v.GetCryptoWithdrawalStatusCtx(context.Background(), currency, WithdrawalID)
+}
+
+func (v *Valr) GetCryptoWithdrawalStatusCtx(ctx context.Context, currency, WithdrawalID string) (status *WithdrawalStatus, err error) {
 	path := fmt.Sprintf("/wallet/crypto/%s/withdraw/%s", currency, WithdrawalID)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -97,16 +131,35 @@ type Deposit struct {
 	CurrencyCode    string
 	ReceiveAddress  string
 	TransactionHash string
-	Amount          float64 `json:",string"`
+	Amount          decimal.Decimal
 	CreatedAt       time.Time
 	Confirmations   uint8
 	Confirmed       bool
-	ConfirmedAt     string
+	ConfirmedAt     time.Time
+}
+
+func (d *Deposit) UnmarshalJSON(data []byte) error {
+	type deposit Deposit
+	aux := struct {
+		*deposit
+		CreatedAt   jsonTime
+		ConfirmedAt jsonTime
+	}{deposit: (*deposit)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.CreatedAt = time.Time(aux.CreatedAt)
+	d.ConfirmedAt = time.Time(aux.ConfirmedAt)
+	return nil
 }
 
 func (v *Valr) GetCryptoDepositHistory(currency string, skip, limit uint32) (history []Deposit, err error) {
+	return v.GetCryptoDepositHistoryCtx(context.Background(), currency, skip, limit)
+}
+
+func (v *Valr) GetCryptoDepositHistoryCtx(ctx context.Context, currency string, skip, limit uint32) (history []Deposit, err error) {
 	path := fmt.Sprintf("/wallet/crypto/%s/deposit/history?skip=%d&limit=%d", currency, skip, limit)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -117,20 +170,39 @@ func (v *Valr) GetCryptoDepositHistory(currency string, skip, limit uint32) (his
 type Withdrawal struct {
 	Currency           string
 	Address            string
-	Amount             float64 `json:",string"`
-	FeeAmount          float64 `json:",string"`
+	Amount             decimal.Decimal
+	FeeAmount          decimal.Decimal
 	TransactionHash    string
 	Confirmations      uint8
-	LastConfirmationAt string
+	LastConfirmationAt time.Time
 	UniqueID           string
-	CreatedAt          string
+	CreatedAt          time.Time
 	Verified           bool
-	Status             string
+	Status             WithdrawalState
+}
+
+func (w *Withdrawal) UnmarshalJSON(data []byte) error {
+	type withdrawal Withdrawal
+	aux := struct {
+		*withdrawal
+		LastConfirmationAt jsonTime
+		CreatedAt          jsonTime
+	}{withdrawal: (*withdrawal)(w)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	w.LastConfirmationAt = time.Time(aux.LastConfirmationAt)
+	w.CreatedAt = time.Time(aux.CreatedAt)
+	return nil
 }
 
 func (v *Valr) GetCryptoWithdrawalHistory(currency string, skip, limit uint32) (history []Withdrawal, err error) {
+	return v.GetCryptoWithdrawalHistoryCtx(context.Background(), currency, skip, limit)
+}
+
+func (v *Valr) GetCryptoWithdrawalHistoryCtx(ctx context.Context, currency string, skip, limit uint32) (history []Withdrawal, err error) {
 	path := fmt.Sprintf("/wallet/crypto/%s/withdraw/history?skip=%d&limit=%d", currency, skip, limit)
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -145,12 +217,29 @@ type BankAccount struct {
 	AccountNumber string
 	BranchCode    string
 	AccountType   string
-	CreatedAt     string
+	CreatedAt     time.Time
+}
+
+func (b *BankAccount) UnmarshalJSON(data []byte) error {
+	type bankAccount BankAccount
+	aux := struct {
+		*bankAccount
+		CreatedAt jsonTime
+	}{bankAccount: (*bankAccount)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.CreatedAt = time.Time(aux.CreatedAt)
+	return nil
 }
 
 func (v *Valr) GetBankAccounts() (banks []BankAccount, err error) {
+	return v.GetBankAccountsCtx(context.Background())
+}
+
+func (v *Valr) GetBankAccountsCtx(ctx context.Context) (banks []BankAccount, err error) {
 	path := "/wallet/fiat/ZAR/accounts"
-	resp, err := v.client.do("GET", path, []byte(""), true)
+	resp, err := v.client.doCtx(ctx, "GET", path, []byte(""), true)
 	if err != nil {
 		return
 	}
@@ -159,12 +248,16 @@ func (v *Valr) GetBankAccounts() (banks []BankAccount, err error) {
 }
 
 type fiatWithdraw struct {
-	LinkedBankAccountId string  `json:"linkedBankAccountId"`
-	Amount              float64 `json:"amount"`
-	Fast                bool    `json:"fast"`
+	LinkedBankAccountId string          `json:"linkedBankAccountId"`
+	Amount              decimal.Decimal `json:"amount"`
+	Fast                bool            `json:"fast"`
+}
+
+func (v *Valr) NewFiatWithdrawal(bankAccountId string, amount decimal.Decimal, fastWithdraw bool) (id *WithdrawalID, err error) {
+	return v.NewFiatWithdrawalCtx(context.Background(), bankAccountId, amount, fastWithdraw)
 }
 
-func (v *Valr) NewFiatWithdrawal(bankAccountId string, amount float64, fastWithdraw bool) (id *WithdrawalID, err error) {
+func (v *Valr) NewFiatWithdrawalCtx(ctx context.Context, bankAccountId string, amount decimal.Decimal, fastWithdraw bool) (id *WithdrawalID, err error) {
 	path := "/wallet/fiat/ZAR/withdraw"
 	withdraw := fiatWithdraw{bankAccountId, amount, fastWithdraw}
 
@@ -173,7 +266,7 @@ func (v *Valr) NewFiatWithdrawal(bankAccountId string, amount float64, fastWithd
 		return
 	}
 
-	resp, err := v.client.do("POST", path, body, true)
+	resp, err := v.client.doCtx(ctx, "POST", path, body, true)
 	if err != nil {
 		return
 	}
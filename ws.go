@@ -0,0 +1,429 @@
+package valr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrStreamClosed is returned when using a stream after Close
+	ErrStreamClosed = errors.New("valr: stream closed")
+	// ErrNotConnected is returned when sending on a stream while it reconnects
+	ErrNotConnected = errors.New("valr: stream not connected")
+)
+
+// StreamConfig controls the heartbeat and reconnection of websocket streams
+type StreamConfig struct {
+	// PingInterval is how often a PING message is sent to keep the connection alive
+	PingInterval time.Duration
+	// StaleTimeout is how long a connection may go without any message before it is considered dead
+	StaleTimeout time.Duration
+	// MinReconnectBackoff and MaxReconnectBackoff bound the exponential wait between reconnection attempts
+	MinReconnectBackoff time.Duration
+	MaxReconnectBackoff time.Duration
+	// MaxReconnectAttempts ends the stream after that many failed attempts in a row, 0 retries forever
+	MaxReconnectAttempts int
+	// DisableReconnect ends the stream on the first connection failure
+	DisableReconnect bool
+}
+
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		PingInterval:        20 * time.Second,
+		StaleTimeout:        60 * time.Second,
+		MinReconnectBackoff: 500 * time.Millisecond,
+		MaxReconnectBackoff: 30 * time.Second,
+	}
+}
+
+func (c StreamConfig) backoff(attempt int) time.Duration {
+	wait := c.MinReconnectBackoff << uint(attempt-1)
+	if wait <= 0 || wait > c.MaxReconnectBackoff {
+		wait = c.MaxReconnectBackoff
+	}
+	// Up to 20% jitter so clients sharing an outage do not reconnect in lockstep
+	return wait - time.Duration(rand.Int63n(int64(wait)/5+1))
+}
+
+type StreamState int
+
+const (
+	// StreamConnected is sent once a connection, or a new connection after a failure, is established
+	StreamConnected StreamState = iota
+	// StreamReconnecting is sent before every reconnection attempt
+	StreamReconnecting
+	// StreamResynced is sent after a reconnection once the stream's subscriptions are replayed
+	StreamResynced
+	// StreamDisconnected is the last state, sent when the stream ends
+	StreamDisconnected
+)
+
+func (s StreamState) String() string {
+	switch s {
+	case StreamConnected:
+		return "connected"
+	case StreamReconnecting:
+		return "reconnecting"
+	case StreamResynced:
+		return "resynced"
+	default:
+		return "disconnected"
+	}
+}
+
+// StreamStateEvent reports a connection state change, Err is the failure that caused it if any
+type StreamStateEvent struct {
+	State   StreamState
+	Attempt int
+	Err     error
+	At      time.Time
+}
+
+// wsMessage is the envelope of every message on Valr's websockets
+type wsMessage struct {
+	Type               string          `json:"type"`
+	CurrencyPairSymbol string          `json:"currencyPairSymbol,omitempty"`
+	ClientMsgID        string          `json:"clientMsgId,omitempty"`
+	Data               json.RawMessage `json:"data,omitempty"`
+}
+
+var pingMessage = wsMessage{Type: "PING"}
+
+// wsConn is a websocket connection to one of Valr's streams
+type wsConn struct {
+	client *client
+	path   string
+	conn   *websocket.Conn
+
+	writeMu sync.Mutex
+}
+
+// wsHeader returns the auth headers for path, signed the same way as REST requests
+func (c *client) wsHeader(path string) (http.Header, error) {
+	header := http.Header{}
+	if c.userAgent != "" {
+		header.Set("User-Agent", c.userAgent)
+	}
+	if c.apiKey == "" {
+		return header, nil
+	}
+
+	timestamp := formatTimestamp(c.clock.now())
+	signature, err := c.signer.Sign(timestamp, "GET", path, "", c.subaccountID)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("X-VALR-API-KEY", c.apiKey)
+	header.Set("X-VALR-SIGNATURE", signature)
+	header.Set("X-VALR-TIMESTAMP", timestamp)
+	if c.subaccountID != "" {
+		header.Set("X-VALR-SUB-ACCOUNT-ID", c.subaccountID)
+	}
+	return header, nil
+}
+
+// dialWS connects to path, the auth headers are signed afresh on every call
+func (c *client) dialWS(ctx context.Context, path string) (*wsConn, error) {
+	header, err := c.wsHeader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: c.httpTimeout,
+	}
+	conn, resp, err := dialer.DialContext(ctx, c.wsBase+path, header)
+	if err != nil {
+		if resp != nil {
+			c.logger.Warn("valr websocket dial failed", "path", path, "status", resp.StatusCode, "error", err)
+		}
+		return nil, err
+	}
+	c.logger.Debug("valr websocket connected", "path", path)
+	return &wsConn{client: c, path: path, conn: conn}, nil
+}
+
+func (w *wsConn) send(v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	w.conn.SetWriteDeadline(time.Now().Add(w.client.httpTimeout))
+	return w.conn.WriteJSON(v)
+}
+
+// read returns the next message, skipping PONGs and messages that are not json objects.
+// It fails when nothing, not even a PONG, arrives within staleTimeout.
+func (w *wsConn) read(staleTimeout time.Duration) (*wsMessage, error) {
+	for {
+		if staleTimeout > 0 {
+			w.conn.SetReadDeadline(time.Now().Add(staleTimeout))
+		}
+		_, data, err := w.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			w.client.logger.Warn("valr websocket message ignored", "path", w.path, "error", err)
+			continue
+		}
+		if msg.Type == "PONG" {
+			continue
+		}
+		if w.client.debug {
+			w.client.logger.Debug("valr websocket message", "path", w.path, "type", msg.Type, "data", redactBody(msg.Data))
+		}
+		return &msg, nil
+	}
+}
+
+// ping sends a PING every interval until stop is closed
+func (w *wsConn) ping(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := w.send(pingMessage); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (w *wsConn) close() error {
+	w.writeMu.Lock()
+	w.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	w.writeMu.Unlock()
+	return w.conn.Close()
+}
+
+// wsStream keeps a stream connected once started: it sends heartbeats, reconnects with
+// backoff when the connection fails or stalls, and hands every message to handle
+type wsStream struct {
+	client *client
+	path   string
+	config StreamConfig
+	handle func(msg *wsMessage) bool
+	end    func()
+	// resync is called after every reconnection, e.g. to replay subscriptions
+	resync func() error
+	states chan StreamStateEvent
+	done   chan struct{}
+
+	connMu sync.Mutex
+	conn   *wsConn
+
+	closeOnce sync.Once
+	mu        sync.Mutex
+	err       error
+}
+
+func (c *client) openStream(ctx context.Context, path string, handle func(msg *wsMessage) bool, end func()) (*wsStream, error) {
+	conn, err := c.dialWS(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	s := &wsStream{
+		client: c,
+		path:   path,
+		config: c.streamConfig,
+		conn:   conn,
+		handle: handle,
+		end:    end,
+		resync: func() error { return nil },
+		states: make(chan StreamStateEvent, 64),
+		done:   make(chan struct{}),
+	}
+	return s, nil
+}
+
+// start runs the read loop, call it once the stream owning handle is set up
+func (s *wsStream) start() {
+	s.setState(StreamConnected, 0, nil)
+	go s.run()
+}
+
+// States returns the channel connection state changes are sent on, it is closed when the stream ends.
+// State changes are dropped while the channel is full.
+func (s *wsStream) States() <-chan StreamStateEvent {
+	return s.states
+}
+
+func (s *wsStream) setState(state StreamState, attempt int, err error) {
+	select {
+	case s.states <- StreamStateEvent{state, attempt, err, time.Now()}:
+	default:
+	}
+}
+
+func (s *wsStream) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *wsStream) run() {
+	defer close(s.states)
+	defer s.end()
+
+	conn := s.currentConn()
+	for {
+		err := s.readLoop(conn)
+		conn.conn.Close()
+		if s.closed() {
+			err = ErrStreamClosed
+		}
+		if err == ErrStreamClosed || s.config.DisableReconnect {
+			s.finish(err)
+			return
+		}
+		s.client.logger.Warn("valr websocket connection lost", "path", s.path, "error", err)
+
+		if conn, err = s.reconnect(err); err != nil {
+			s.finish(err)
+			return
+		}
+	}
+}
+
+func (s *wsStream) finish(err error) {
+	s.setErr(err)
+	s.setState(StreamDisconnected, 0, s.Err())
+}
+
+// readLoop hands messages to handle until the connection fails or handle gives up
+func (s *wsStream) readLoop(conn *wsConn) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go conn.ping(s.config.PingInterval, stop)
+
+	for {
+		msg, err := conn.read(s.config.StaleTimeout)
+		if err != nil {
+			return err
+		}
+		if !s.handle(msg) {
+			return ErrStreamClosed
+		}
+	}
+}
+
+// reconnect dials until it succeeds, the stream is closed or MaxReconnectAttempts is reached
+func (s *wsStream) reconnect(cause error) (*wsConn, error) {
+	s.setConn(nil)
+	for attempt := 1; ; attempt++ {
+		s.setState(StreamReconnecting, attempt, cause)
+		select {
+		case <-s.done:
+			return nil, ErrStreamClosed
+		case <-time.After(s.config.backoff(attempt)):
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-s.done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		conn, err := s.client.dialWS(ctx, s.path)
+		cancel()
+		if err == nil {
+			s.setConn(conn)
+			if s.closed() {
+				conn.close()
+				return nil, ErrStreamClosed
+			}
+			s.setState(StreamConnected, attempt, nil)
+			if err = s.resync(); err == nil {
+				s.client.logger.Info("valr websocket reconnected", "path", s.path, "attempt", attempt)
+				s.setState(StreamResynced, attempt, nil)
+				return conn, nil
+			}
+			s.setConn(nil)
+			conn.close()
+		}
+
+		cause = err
+		s.client.logger.Warn("valr websocket reconnect failed", "path", s.path, "attempt", attempt, "error", err)
+		if s.config.MaxReconnectAttempts > 0 && attempt >= s.config.MaxReconnectAttempts {
+			return nil, err
+		}
+	}
+}
+
+func (s *wsStream) currentConn() *wsConn {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	return s.conn
+}
+
+func (s *wsStream) setConn(conn *wsConn) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	s.conn = conn
+}
+
+// Connected reports whether the stream currently has a live connection
+func (s *wsStream) Connected() bool {
+	return !s.closed() && s.currentConn() != nil
+}
+
+func (s *wsStream) send(v interface{}) error {
+	if s.closed() {
+		return ErrStreamClosed
+	}
+	conn := s.currentConn()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	return conn.send(v)
+}
+
+// Err returns why the stream ended
+func (s *wsStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *wsStream) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed() {
+		err = ErrStreamClosed
+	}
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *wsStream) Close() error {
+	err := ErrStreamClosed
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = nil
+		if conn := s.currentConn(); conn != nil {
+			err = conn.close()
+		}
+	})
+	return err
+}